@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zinc-sig/minio-resource/pkg/models"
+	"github.com/zinc-sig/minio-resource/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// concourseEnv are the build metadata variables Concourse sets for in and
+// out, with the values used when running locally
+var concourseEnv = map[string]string{
+	"ATC_EXTERNAL_URL":    "http://localhost:8080",
+	"BUILD_ID":            "1",
+	"BUILD_NAME":          "1",
+	"BUILD_JOB_NAME":      "local",
+	"BUILD_PIPELINE_NAME": "local",
+	"BUILD_TEAM_NAME":     "main",
+}
+
+// options holds the flags shared by all subcommands
+type options struct {
+	config              string
+	endpoint            string
+	accessKey           string
+	secretKey           string
+	bucket              string
+	pathPrefix          string
+	region              string
+	useSSL              string
+	skipSSLVerification bool
+	versionPath         string
+	versionETag         string
+	versionModified     string
+	source              keyValues
+	params              keyValues
+	env                 keyValues
+	cacheDir            string
+	dryRun              bool
+	printRequest        bool
+	compact             bool
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(nil)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	switch command {
+	case "check", "in", "out":
+	case "-h", "-help", "--help", "help":
+		usage(nil)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		usage(nil)
+		os.Exit(2)
+	}
+
+	// Parse flags
+	flags, opts := newFlagSet(command)
+	flags.Parse(os.Args[2:])
+
+	// Defaults from the environment are applied here rather than as flag
+	// defaults, which usage would print
+	if opts.accessKey == "" {
+		opts.accessKey = os.Getenv("MINIO_ACCESS_KEY")
+	}
+	if opts.secretKey == "" {
+		opts.secretKey = os.Getenv("MINIO_SECRET_KEY")
+	}
+	if opts.cacheDir == "" {
+		opts.cacheDir = os.Getenv("MINIO_RESOURCE_CACHE_DIR")
+	}
+
+	// The directory argument of in and out
+	dir := flags.Arg(0)
+	if command != "check" && dir == "" {
+		fatal("%s requires a directory argument", command)
+	}
+	if command == "check" && opts.dryRun {
+		fatal("-dry-run applies only to in and out, check never modifies the bucket")
+	}
+
+	// Build the request
+	request, err := buildRequest(command, *opts)
+	if err != nil {
+		fatal("failed to build request: %v", err)
+	}
+
+	if opts.printRequest {
+		printJSON(redact(request), opts.compact)
+		return
+	}
+
+	// Simulate the environment Concourse provides
+	for name, value := range concourseEnv {
+		if _, ok := os.LookupEnv(name); !ok {
+			os.Setenv(name, value)
+		}
+	}
+	for name, value := range opts.env {
+		os.Setenv(name, fmt.Sprint(value))
+	}
+
+	// Run the command
+	ctx, stop := resource.WithInterrupt(context.Background())
+	response, err := run(ctx, command, request, dir)
+	if sig := stop(); sig != nil {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(resource.ExitCode(sig))
+	}
+	if err != nil {
+		fatal("%v", err)
+	}
+	printJSON(response, opts.compact)
+}
+
+// buildRequest combines the config file and flags into a request, in the
+// JSON form the resource scripts read from stdin
+func buildRequest(command string, opts options) (map[string]any, error) {
+	request := map[string]any{}
+	if opts.config != "" {
+		data, err := os.ReadFile(opts.config)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &request); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", opts.config, err)
+		}
+	}
+
+	source := section(request, "source")
+	setString(source, "endpoint", opts.endpoint)
+	setString(source, "access_key", opts.accessKey)
+	setString(source, "secret_key", opts.secretKey)
+	setString(source, "bucket", opts.bucket)
+	setString(source, "path_prefix", opts.pathPrefix)
+	setString(source, "region", opts.region)
+	setString(source, "use_ssl", opts.useSSL)
+	if opts.skipSSLVerification {
+		source["skip_ssl_verification"] = true
+	}
+	for name, value := range opts.source {
+		source[name] = value
+	}
+
+	if opts.versionPath != "" || opts.versionETag != "" || opts.versionModified != "" {
+		version := section(request, "version")
+		setString(version, "path", opts.versionPath)
+		setString(version, "etag", opts.versionETag)
+		setString(version, "last_modified", opts.versionModified)
+	}
+
+	if len(opts.params) > 0 || opts.dryRun || (command == "in" && opts.cacheDir != "") {
+		params := section(request, "params")
+		for name, value := range opts.params {
+			params[name] = value
+		}
+		if opts.dryRun {
+			params["dry_run"] = true
+		}
+		if _, ok := params["cache_dir"]; !ok && command == "in" && opts.cacheDir != "" {
+			params["cache_dir"] = opts.cacheDir
+		}
+	}
+
+	// Concourse does not send params to check or a version to out
+	switch command {
+	case "check":
+		delete(request, "params")
+	case "out":
+		delete(request, "version")
+	}
+	if command == "in" && request["version"] == nil {
+		request["version"] = map[string]any{}
+	}
+
+	return request, nil
+}
+
+// run decodes the request exactly as the resource scripts do and runs it
+func run(ctx context.Context, command string, raw map[string]any, dir string) (any, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case "check":
+		var request models.CheckRequest
+		if err := models.DecodeRequest(bytes.NewReader(data), &request); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		return resource.Check(ctx, request)
+	case "in":
+		var request models.InRequest
+		if err := models.DecodeRequest(bytes.NewReader(data), &request); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		return resource.In(ctx, request, dir)
+	default:
+		var request models.OutRequest
+		if err := models.DecodeRequest(bytes.NewReader(data), &request); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		return resource.Out(ctx, request, dir)
+	}
+}
+
+// redact returns a copy of the request with credentials hidden
+func redact(request map[string]any) map[string]any {
+	out := make(map[string]any, len(request))
+	for key, value := range request {
+		out[key] = value
+	}
+
+	source := make(map[string]any)
+	for key, value := range section(request, "source") {
+		if key == "access_key" || key == "secret_key" {
+			value = "<redacted>"
+		}
+		source[key] = value
+	}
+	out["source"] = source
+	return out
+}
+
+// section returns the object stored under key, creating it if needed
+func section(request map[string]any, key string) map[string]any {
+	m, ok := request[key].(map[string]any)
+	if !ok {
+		m = make(map[string]any)
+		request[key] = m
+	}
+	return m
+}
+
+func setString(m map[string]any, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+func printJSON(v any, compact bool) {
+	encoder := json.NewEncoder(os.Stdout)
+	if !compact {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		fatal("failed to encode response: %v", err)
+	}
+}
+
+// keyValues implements a repeatable name=value flag. Values are parsed as
+// YAML, so numbers, booleans and objects keep their type.
+type keyValues map[string]any
+
+func (kv keyValues) String() string { return "" }
+
+func (kv keyValues) Set(arg string) error {
+	name, raw, ok := strings.Cut(arg, "=")
+	if !ok {
+		return fmt.Errorf("expected name=value, got %q", arg)
+	}
+
+	var value any
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	kv[name] = value
+	return nil
+}
+
+// newFlagSet defines the flags of a subcommand, returning the options
+// they are parsed into
+func newFlagSet(command string) (*flag.FlagSet, *options) {
+	opts := &options{source: keyValues{}, params: keyValues{}, env: keyValues{}}
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.StringVar(&opts.config, "config", "", "YAML or JSON file with source, version and params")
+	flags.StringVar(&opts.endpoint, "endpoint", "", "Minio endpoint")
+	flags.StringVar(&opts.accessKey, "access-key", "", "access key (default $MINIO_ACCESS_KEY)")
+	flags.StringVar(&opts.secretKey, "secret-key", "", "secret key (default $MINIO_SECRET_KEY)")
+	flags.StringVar(&opts.bucket, "bucket", "", "bucket name")
+	flags.StringVar(&opts.pathPrefix, "path-prefix", "", "path prefix")
+	flags.StringVar(&opts.region, "region", "", "region")
+	flags.StringVar(&opts.useSSL, "use-ssl", "", "use SSL/TLS, true or false")
+	flags.BoolVar(&opts.skipSSLVerification, "skip-ssl-verification", false, "skip SSL certificate verification")
+	flags.StringVar(&opts.versionPath, "version-path", "", "path of the current version")
+	flags.StringVar(&opts.versionETag, "version-etag", "", "etag of the current version")
+	flags.StringVar(&opts.versionModified, "version-last-modified", "", "last modified time of the current version (RFC3339)")
+	flags.Var(opts.source, "source", "set a source field as name=value (repeatable)")
+	flags.Var(opts.params, "param", "set a param as name=value (repeatable)")
+	flags.Var(opts.env, "env", "set a Concourse build variable as NAME=value (repeatable)")
+	flags.StringVar(&opts.cacheDir, "cache-dir", "", "keep downloads here and reuse unchanged objects, e.g. a task cache (default $MINIO_RESOURCE_CACHE_DIR)")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "set params.dry_run, listing what in or out would do without doing it")
+	flags.BoolVar(&opts.printRequest, "print-request", false, "print the request that would be sent and exit")
+	flags.BoolVar(&opts.compact, "compact", false, "print the response as compact JSON")
+	flags.Usage = func() { usage(flags) }
+	return flags, opts
+}
+
+// usage prints the help text with the flags of flags, or of a new flag set
+// if it is nil
+func usage(flags *flag.FlagSet) {
+	if flags == nil {
+		flags, _ = newFlagSet("")
+	}
+
+	fmt.Fprintf(os.Stderr, `usage: minio-resource <command> [flags] [directory]
+
+Runs the resource locally, building the request from a config file and
+flags instead of stdin JSON.
+
+Commands:
+  check                 detect new versions
+  in <destination>      download files to destination
+  out <source>          upload files from source
+
+Examples:
+  minio-resource check -config source.yml
+  minio-resource in -config source.yml -param parallel=10 ./downloads
+  minio-resource out -config source.yml -dry-run ./build
+  minio-resource out -endpoint localhost:9000 -bucket test -use-ssl=false \
+    -param upload_enabled=true -param file='*.tgz' ./build
+
+Flags:
+`)
+	flags.SetOutput(os.Stderr)
+	flags.PrintDefaults()
+}
+
+func fatal(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
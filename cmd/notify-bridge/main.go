@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+// config holds the bridge settings, read from flags or the environment
+type config struct {
+	listen              string
+	concourseURL        string
+	team                string
+	pipeline            string
+	resource            string
+	webhookToken        string
+	authToken           string
+	prefix              string
+	debounce            time.Duration
+	skipSSLVerification bool
+}
+
+func main() {
+	// Parse configuration
+	var cfg config
+	flag.StringVar(&cfg.listen, "listen", env("LISTEN_ADDR", ":8080"), "address to receive MinIO webhook events on")
+	flag.StringVar(&cfg.concourseURL, "concourse-url", env("CONCOURSE_URL", ""), "external URL of the Concourse web node")
+	flag.StringVar(&cfg.team, "team", env("CONCOURSE_TEAM", "main"), "Concourse team of the pipeline")
+	flag.StringVar(&cfg.pipeline, "pipeline", env("CONCOURSE_PIPELINE", ""), "pipeline containing the resource")
+	flag.StringVar(&cfg.resource, "resource", env("CONCOURSE_RESOURCE", ""), "resource to check when objects are created")
+	flag.StringVar(&cfg.webhookToken, "webhook-token", env("CONCOURSE_WEBHOOK_TOKEN", ""), "webhook_token configured on the resource")
+	flag.StringVar(&cfg.authToken, "auth-token", env("MINIO_AUTH_TOKEN", ""), "auth_token MinIO sends with each event (optional)")
+	flag.StringVar(&cfg.prefix, "prefix", env("OBJECT_PREFIX", ""), "only react to objects with this key prefix (optional)")
+	flag.DurationVar(&cfg.debounce, "debounce", 5*time.Second, "coalesce events received within this window into one check")
+	flag.BoolVar(&cfg.skipSSLVerification, "skip-ssl-verification", os.Getenv("CONCOURSE_SKIP_SSL_VERIFICATION") == "true", "skip verification of the Concourse certificate")
+	flag.Parse()
+
+	if err := validateConfig(cfg); err != nil {
+		fatal("invalid configuration: %v", err)
+	}
+
+	// Trigger checks in the background, coalescing bursts of events
+	triggers := make(chan struct{}, 1)
+	go runChecks(cfg, triggers)
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		handleEvents(cfg, triggers, w, r)
+	})
+
+	fmt.Fprintf(os.Stderr, "Listening for MinIO events on %s, checking %s/%s/%s\n",
+		cfg.listen, cfg.team, cfg.pipeline, cfg.resource)
+	if err := http.ListenAndServe(cfg.listen, nil); err != nil {
+		fatal("failed to serve: %v", err)
+	}
+}
+
+// handleEvents accepts a MinIO webhook delivery and schedules a check if any
+// of its records created an object under the configured prefix
+func handleEvents(cfg config, triggers chan<- struct{}, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cfg.authToken != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.authToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var body struct {
+		Records []notification.Event `json:"Records"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid event payload", http.StatusBadRequest)
+		return
+	}
+
+	for _, record := range body.Records {
+		if !strings.HasPrefix(record.EventName, "s3:ObjectCreated:") {
+			continue
+		}
+
+		// Object keys in notifications are URL encoded
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			key = record.S3.Object.Key
+		}
+		if !strings.HasPrefix(key, cfg.prefix) {
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "Received %s for %s\n", record.EventName, key)
+		select {
+		case triggers <- struct{}{}:
+		default:
+			// A check is already pending
+		}
+		break
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runChecks calls the Concourse check webhook for each trigger, waiting for
+// the debounce window first so bulk uploads cause a single check
+func runChecks(cfg config, triggers chan struct{}) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	if cfg.skipSSLVerification {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	for range triggers {
+		time.Sleep(cfg.debounce)
+
+		// Drop triggers that arrived during the debounce window
+		select {
+		case <-triggers:
+		default:
+		}
+
+		if err := triggerCheck(client, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error triggering check: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Triggered check of %s/%s/%s\n", cfg.team, cfg.pipeline, cfg.resource)
+	}
+}
+
+// triggerCheck asks Concourse to check the resource via its webhook endpoint
+func triggerCheck(client *http.Client, cfg config) error {
+	checkURL := fmt.Sprintf("%s/api/v1/teams/%s/pipelines/%s/resources/%s/check/webhook?webhook_token=%s",
+		strings.TrimSuffix(cfg.concourseURL, "/"),
+		url.PathEscape(cfg.team),
+		url.PathEscape(cfg.pipeline),
+		url.PathEscape(cfg.resource),
+		url.QueryEscape(cfg.webhookToken),
+	)
+
+	resp, err := client.Post(checkURL, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to call concourse: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("concourse returned %s", resp.Status)
+	}
+	return nil
+}
+
+func validateConfig(cfg config) error {
+	if cfg.concourseURL == "" {
+		return fmt.Errorf("concourse-url is required")
+	}
+	if cfg.pipeline == "" {
+		return fmt.Errorf("pipeline is required")
+	}
+	if cfg.resource == "" {
+		return fmt.Errorf("resource is required")
+	}
+	if cfg.webhookToken == "" {
+		return fmt.Errorf("webhook-token is required")
+	}
+	return nil
+}
+
+func env(name, def string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return def
+}
+
+func fatal(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
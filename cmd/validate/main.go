@@ -0,0 +1,478 @@
+package main
+
+//go:generate sh -c "go run . -schema > ../../schema.json"
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zinc-sig/minio-resource/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// finding is a problem found at a location in the validated document
+type finding struct {
+	location string
+	err      error
+}
+
+func main() {
+	// Parse flags
+	var (
+		printSchema  bool
+		resourceType string
+		requestKind  string
+		vars         = varFlag{}
+	)
+	flag.BoolVar(&printSchema, "schema", false, "print the JSON Schema of the resource configuration and exit")
+	flag.StringVar(&resourceType, "type", "minio-resource", "name of the resource type in pipelines")
+	flag.StringVar(&requestKind, "request", "", "validate request JSON as check, in or out (guessed if empty)")
+	flag.Var(varSetter{vars}, "v", "set a pipeline variable as name=value (repeatable)")
+	flag.Var(varFileLoader{vars}, "l", "load pipeline variables from a YAML file (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] [pipeline.yml | request.json ...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Validates pipeline YAML or request JSON against the resource schema.\n")
+		fmt.Fprintf(os.Stderr, "Reads stdin when no files are given.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if printSchema {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(models.ResourceSchema()); err != nil {
+			fatal("failed to encode schema: %v", err)
+		}
+		return
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	// Validate each document
+	problems := 0
+	for _, file := range files {
+		doc, err := readDocument(file)
+		if err != nil {
+			fatal("failed to read %s: %v", file, err)
+		}
+
+		var findings []finding
+		if pipeline, ok := doc.(map[string]any); ok && pipeline["resources"] != nil {
+			findings = validatePipeline(pipeline, resourceType, vars)
+		} else {
+			findings, err = validateRequest(doc, requestKind, vars)
+			if err != nil {
+				fatal("failed to validate %s: %v", file, err)
+			}
+		}
+
+		name := file
+		if name == "-" {
+			name = "stdin"
+		}
+		for _, f := range findings {
+			for _, err := range flatten(f.err) {
+				fmt.Printf("%s: %s: %v\n", name, f.location, err)
+				problems++
+			}
+		}
+	}
+
+	if problems > 0 {
+		fmt.Fprintf(os.Stderr, "Found %d problems\n", problems)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "No problems found\n")
+}
+
+// readDocument parses a YAML or JSON file, or stdin for "-"
+func readDocument(file string) (any, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// validatePipeline checks the source of every resource of the given type
+// and the params of every get and put step using one
+func validatePipeline(pipeline map[string]any, resourceType string, vars varFlag) []finding {
+	// Resource types that refer to this resource
+	types := map[string]bool{resourceType: true}
+	for _, rt := range list(pipeline["resource_types"]) {
+		rt := object(rt)
+		name, _ := rt["name"].(string)
+		repository, _ := object(rt["source"])["repository"].(string)
+		if name != "" && strings.HasSuffix(repository, "minio-resource") {
+			types[name] = true
+		}
+	}
+
+	// Resources of those types
+	var findings []finding
+	ours := make(map[string]bool)
+	for _, r := range list(pipeline["resources"]) {
+		r := object(r)
+		name, _ := r["name"].(string)
+		typ, _ := r["type"].(string)
+		if !types[typ] {
+			continue
+		}
+
+		ours[name] = true
+		if err := checkSource(r["source"], vars); err != nil {
+			findings = append(findings, finding{fmt.Sprintf("resource %s source", name), err})
+		}
+	}
+
+	// Steps using those resources, at any depth of the job plans
+	for _, job := range list(pipeline["jobs"]) {
+		job := object(job)
+		jobName, _ := job["name"].(string)
+		walkSteps(job, func(step map[string]any) {
+			if name, ok := step["get"].(string); ok {
+				resource := stepResource(step, name)
+				if ours[resource] {
+					if err := checkParams(step["params"], &models.InParams{}, vars); err != nil {
+						findings = append(findings, finding{fmt.Sprintf("job %s get %s params", jobName, name), err})
+					}
+				}
+			}
+			if name, ok := step["put"].(string); ok {
+				resource := stepResource(step, name)
+				if ours[resource] {
+					if err := checkParams(step["params"], &models.OutParams{}, vars); err != nil {
+						findings = append(findings, finding{fmt.Sprintf("job %s put %s params", jobName, name), err})
+					}
+					if err := checkParams(step["get_params"], &models.InParams{}, vars); err != nil {
+						findings = append(findings, finding{fmt.Sprintf("job %s put %s get_params", jobName, name), err})
+					}
+				}
+			}
+		})
+	}
+
+	return findings
+}
+
+// validateRequest checks a check, in or out request as read by the scripts
+func validateRequest(doc any, kind string, vars varFlag) ([]finding, error) {
+	request := object(doc)
+	if kind == "" {
+		switch {
+		case request["params"] != nil && request["version"] != nil:
+			kind = "in"
+		case request["params"] != nil:
+			kind = "out"
+		default:
+			kind = "check"
+		}
+	}
+
+	var findings []finding
+	if err := checkSource(request["source"], vars); err != nil {
+		findings = append(findings, finding{"source", err})
+	}
+
+	if v, ok := request["version"]; ok {
+		data, _ := json.Marshal(v)
+		var version models.Version
+		if err := json.Unmarshal(data, &version); err != nil {
+			findings = append(findings, finding{"version", err})
+		}
+	}
+
+	switch kind {
+	case "check":
+	case "in":
+		if err := checkParams(request["params"], &models.InParams{}, vars); err != nil {
+			findings = append(findings, finding{"params", err})
+		}
+	case "out":
+		if err := checkParams(request["params"], &models.OutParams{}, vars); err != nil {
+			findings = append(findings, finding{"params", err})
+		}
+	default:
+		return nil, fmt.Errorf("unknown request kind %q, expected check, in or out", kind)
+	}
+	return findings, nil
+}
+
+// checkSource decodes and validates a source. Fields whose value uses a
+// variable that is not set are skipped.
+func checkSource(raw any, vars varFlag) error {
+	if raw == nil {
+		return errors.New("source is required")
+	}
+
+	resolved, skipped := vars.resolve(raw, "")
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		return err
+	}
+
+	// Report decoding and validation problems together, without validating
+	// the fields that failed to decode
+	var source models.Source
+	decodeErr := models.DecodeStrict("", data, &source)
+	for _, err := range flatten(decodeErr) {
+		var fieldErr *models.FieldError
+		if errors.As(err, &fieldErr) {
+			skipped = append(skipped, fieldErr.Path)
+		}
+	}
+	return errors.Join(decodeErr, skipFields(source.Validate(), skipped))
+}
+
+// checkParams decodes params strictly into target. Fields whose value uses
+// a variable that is not set are skipped.
+func checkParams(raw any, target any, vars varFlag) error {
+	if raw == nil {
+		return nil
+	}
+
+	resolved, skipped := vars.resolve(raw, "")
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		return err
+	}
+	if err := models.DecodeStrict("", data, target); err != nil {
+		return err
+	}
+
+	// Params with cross-field rules, such as the out action
+	if v, ok := target.(interface{ Validate() error }); ok {
+		return skipFields(v.Validate(), skipped)
+	}
+	return nil
+}
+
+// skipFields drops the validation errors about skipped fields
+func skipFields(err error, skipped []string) error {
+	var kept []error
+	for _, err := range flatten(err) {
+		var fieldErr *models.FieldError
+		if errors.As(err, &fieldErr) && isSkipped(fieldErr.Path, skipped) {
+			continue
+		}
+		kept = append(kept, err)
+	}
+	return errors.Join(kept...)
+}
+
+func isSkipped(path string, skipped []string) bool {
+	for _, s := range skipped {
+		if path == s || strings.HasPrefix(path, s+".") || strings.HasPrefix(path, s+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// walkSteps calls fn for every object nested in v
+func walkSteps(v any, fn func(map[string]any)) {
+	switch v := v.(type) {
+	case map[string]any:
+		fn(v)
+		for _, key := range sortedKeys(v) {
+			walkSteps(v[key], fn)
+		}
+	case []any:
+		for _, item := range v {
+			walkSteps(item, fn)
+		}
+	}
+}
+
+// stepResource returns the resource a get or put step uses
+func stepResource(step map[string]any, name string) string {
+	if resource, ok := step["resource"].(string); ok {
+		return resource
+	}
+	return name
+}
+
+// flatten splits joined errors into their parts
+func flatten(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, flatten(err)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func object(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// varPattern matches a ((var)) placeholder
+var varPattern = regexp.MustCompile(`\(\(\s*([^()]+?)\s*\)\)`)
+
+// varFlag holds the pipeline variables given with -v and -l
+type varFlag map[string]any
+
+// resolve interpolates variables into v. Object keys whose value uses an
+// unset variable are removed and their paths returned.
+func (vars varFlag) resolve(v any, path string) (any, []string) {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		var skipped []string
+		for _, key := range sortedKeys(v) {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			value, ok := vars.interpolate(v[key])
+			if !ok {
+				skipped = append(skipped, childPath)
+				continue
+			}
+			resolved, more := vars.resolve(value, childPath)
+			out[key] = resolved
+			skipped = append(skipped, more...)
+		}
+		return out, skipped
+	case []any:
+		out := make([]any, len(v))
+		var skipped []string
+		for i, item := range v {
+			value, _ := vars.interpolate(item)
+			resolved, more := vars.resolve(value, fmt.Sprintf("%s[%d]", path, i))
+			out[i] = resolved
+			skipped = append(skipped, more...)
+		}
+		return out, skipped
+	}
+	return v, nil
+}
+
+// interpolate replaces the variables in a string value, reporting false if
+// any of them is not set. A value that is a single variable takes the type
+// of the variable's value.
+func (vars varFlag) interpolate(v any) (any, bool) {
+	s, ok := v.(string)
+	if !ok || !varPattern.MatchString(s) {
+		return v, true
+	}
+
+	if m := varPattern.FindStringSubmatch(s); m[0] == s {
+		return vars.lookup(m[1])
+	}
+
+	resolved := true
+	out := varPattern.ReplaceAllStringFunc(s, func(match string) string {
+		value, ok := vars.lookup(varPattern.FindStringSubmatch(match)[1])
+		if !ok {
+			resolved = false
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+	return out, resolved
+}
+
+// lookup finds a variable by name, following dotted fields into its value
+func (vars varFlag) lookup(name string) (any, bool) {
+	if value, ok := vars[name]; ok {
+		return value, true
+	}
+
+	parts := strings.Split(name, ".")
+	value, ok := vars[parts[0]]
+	for _, field := range parts[1:] {
+		if !ok {
+			break
+		}
+		value, ok = object(value)[field]
+	}
+	return value, ok
+}
+
+// varSetter implements -v name=value
+type varSetter struct{ vars varFlag }
+
+func (s varSetter) String() string { return "" }
+
+func (s varSetter) Set(arg string) error {
+	name, raw, ok := strings.Cut(arg, "=")
+	if !ok {
+		return fmt.Errorf("expected name=value, got %q", arg)
+	}
+
+	var value any
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	s.vars[name] = value
+	return nil
+}
+
+// varFileLoader implements -l vars.yml
+type varFileLoader struct{ vars varFlag }
+
+func (l varFileLoader) String() string { return "" }
+
+func (l varFileLoader) Set(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	for name, value := range values {
+		l.vars[name] = value
+	}
+	return nil
+}
+
+func fatal(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,240 @@
+// Package logging builds the leveled loggers the resource writes to stderr.
+// Concourse shows stderr as the build log, so the default text format is
+// plain lines rather than key=value records, and credentials are redacted
+// from every message.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// redacted replaces credentials in log output
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are always redacted
+var sensitiveKeys = map[string]bool{
+	"access_key":    true,
+	"secret_key":    true,
+	"password":      true,
+	"token":         true,
+	"authorization": true,
+}
+
+// Options configures a logger
+type Options struct {
+	// Level is debug, info, warn or error, defaulting to info
+	Level string
+	// Format is text or json, defaulting to text
+	Format string
+	// Secrets are removed from messages and attribute values
+	Secrets []string
+}
+
+// ParseLevel parses a level name, where the empty string is info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("%q must be one of debug, info, warn or error", name)
+}
+
+// New returns a logger writing to w. An invalid level falls back to info,
+// as levels are validated when the configuration is decoded.
+func New(w io.Writer, opts Options) *slog.Logger {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+
+	var handler slog.Handler
+	if opts.Format == FormatJSON {
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	} else {
+		handler = &textHandler{w: w, level: level, mu: &sync.Mutex{}}
+	}
+
+	var secrets []string
+	for _, secret := range opts.Secrets {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return slog.New(&redactHandler{next: handler, secrets: secrets})
+}
+
+// Discard returns a logger that drops everything
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// textHandler writes records as "LEVEL: message key=value", leaving out the
+// level for info and the time, which Concourse already shows
+type textHandler struct {
+	w      io.Writer
+	level  slog.Level
+	attrs  []slog.Attr
+	prefix string
+	mu     *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+	if record.Level != slog.LevelInfo {
+		buf.WriteString(record.Level.String())
+		buf.WriteString(": ")
+	}
+	buf.WriteString(record.Message)
+
+	for _, attr := range h.attrs {
+		writeAttr(&buf, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&buf, h.prefix, attr)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		attr.Key = h.prefix + attr.Key
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// writeAttr appends " key=value", flattening groups into dotted keys
+func writeAttr(buf *bytes.Buffer, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			writeAttr(buf, prefix, member)
+		}
+		return
+	}
+
+	var value string
+	switch attr.Value.Kind() {
+	case slog.KindDuration:
+		value = attr.Value.Duration().Round(time.Millisecond).String()
+	case slog.KindTime:
+		value = attr.Value.Time().Format(time.RFC3339)
+	default:
+		value = attr.Value.String()
+	}
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(prefix)
+	buf.WriteString(attr.Key)
+	buf.WriteByte('=')
+	buf.WriteString(value)
+}
+
+// redactHandler removes credentials before records reach the next handler
+type redactHandler struct {
+	next    slog.Handler
+	secrets []string
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	clean := slog.NewRecord(record.Time, record.Level, h.redact(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		clean.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, clean)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		clean[i] = h.redactAttr(attr)
+	}
+	return &redactHandler{next: h.next.WithAttrs(clean), secrets: h.secrets}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name), secrets: h.secrets}
+}
+
+func (h *redactHandler) redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch {
+	case sensitiveKeys[strings.ToLower(attr.Key)]:
+		attr.Value = slog.StringValue(redacted)
+	case attr.Value.Kind() == slog.KindGroup:
+		group := attr.Value.Group()
+		clean := make([]slog.Attr, len(group))
+		for i, member := range group {
+			clean[i] = h.redactAttr(member)
+		}
+		attr.Value = slog.GroupValue(clean...)
+	case attr.Value.Kind() == slog.KindString, attr.Value.Kind() == slog.KindAny:
+		if s := attr.Value.String(); h.redact(s) != s {
+			attr.Value = slog.StringValue(h.redact(s))
+		}
+	}
+	return attr
+}
+
+func (h *redactHandler) redact(s string) string {
+	for _, secret := range h.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
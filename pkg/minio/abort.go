@@ -0,0 +1,100 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// abortTimeout bounds the cleanup of an interrupted upload
+const abortTimeout = 10 * time.Second
+
+// uploadRecorder collects the IDs of the multipart uploads started by the
+// requests of one upload or copy, as seen by limitedTransport, so that an
+// interrupted upload can abort exactly its own
+type uploadRecorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+type uploadRecorderKey struct{}
+
+// recordUploads returns a context whose requests record the multipart
+// uploads they start or continue
+func recordUploads(ctx context.Context) (context.Context, *uploadRecorder) {
+	recorder := &uploadRecorder{}
+	return context.WithValue(ctx, uploadRecorderKey{}, recorder), recorder
+}
+
+// observe records the upload ID of a request that continues a multipart
+// upload, or of the response that starts one
+func (r *uploadRecorder) observe(req *http.Request, resp *http.Response) {
+	query := req.URL.Query()
+	if id := query.Get("uploadId"); id != "" {
+		r.record(id)
+		return
+	}
+	if req.Method != http.MethodPost || !query.Has("uploads") || resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	// The response is small, so it is read here and handed on as is
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if xml.Unmarshal(data, &initiated) == nil && initiated.UploadID != "" {
+		r.record(initiated.UploadID)
+	}
+}
+
+func (r *uploadRecorder) record(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, known := range r.ids {
+		if known == id {
+			return
+		}
+	}
+	r.ids = append(r.ids, id)
+}
+
+func (r *uploadRecorder) uploadIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids...)
+}
+
+// abortUploads removes the parts of the multipart uploads of an interrupted
+// upload or copy. minio-go aborts failed uploads itself, but with the
+// context of the upload, which is already canceled when the upload was
+// interrupted, so the parts would be left behind taking up storage. Only
+// the recorded uploads are aborted, as other builds may be writing the
+// same key.
+func (c *Client) abortUploads(bucket, objectPath string, recorder *uploadRecorder) {
+	ctx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+	defer cancel()
+
+	core := minio.Core{Client: c.client}
+	for _, id := range recorder.uploadIDs() {
+		err := core.AbortMultipartUpload(ctx, bucket, objectPath, id)
+		if err != nil && minio.ToErrorResponse(err).Code == minio.NoSuchUpload {
+			// Already aborted or completed
+			continue
+		}
+		if err != nil {
+			c.logger.Warn("Failed to abort multipart upload", "key", objectPath, "upload_id", id, "error", err)
+			continue
+		}
+		c.logger.Debug("Aborted multipart upload", "key", objectPath, "upload_id", id)
+	}
+}
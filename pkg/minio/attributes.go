@@ -0,0 +1,110 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// User metadata keys of preserved file attributes. The mode is the octal
+// permission bits and the mtime is in Unix seconds with a fractional part,
+// the format rclone uses, so files uploaded by either round-trip.
+const (
+	metaMode  = "mode"
+	metaMtime = "mtime"
+)
+
+// FileAttributes returns the user metadata preserving the mode and
+// modification time of a file, for PutOptions.UserMetadata
+func FileAttributes(info fs.FileInfo) map[string]string {
+	mtime := info.ModTime()
+	return map[string]string{
+		metaMode:  strconv.FormatUint(uint64(info.Mode().Perm()), 8),
+		metaMtime: fmt.Sprintf("%d.%09d", mtime.Unix(), mtime.Nanosecond()),
+	}
+}
+
+// applyAttributes sets the mode and modification time of a downloaded file
+// from the object's mode and mtime metadata, using the object's last
+// modified time when there is no mtime. Listings without metadata are
+// completed with a stat of the object.
+func (c *Client) applyAttributes(ctx context.Context, object ObjectInfo, path string) error {
+	metadata := object.Metadata
+	if metadata == nil {
+		info, err := c.client.StatObject(ctx, c.bucket, object.Path, minio.StatObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to stat object %s: %w", object.Path, err)
+		}
+		metadata = info.UserMetadata
+	}
+
+	if value, ok := lookupMetadata(metadata, metaMode); ok {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return fmt.Errorf("invalid mode metadata %q on %s", value, object.Path)
+		}
+		if err := os.Chmod(path, fs.FileMode(mode).Perm()); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", path, err)
+		}
+	}
+
+	mtime := object.LastModified
+	if value, ok := lookupMetadata(metadata, metaMtime); ok {
+		t, err := parseMtime(value)
+		if err != nil {
+			return fmt.Errorf("invalid mtime metadata %q on %s", value, object.Path)
+		}
+		mtime = t
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		return fmt.Errorf("failed to set modification time of %s: %w", path, err)
+	}
+	return nil
+}
+
+// lookupMetadata finds a user metadata value by key. Stats return keys
+// without the X-Amz-Meta- prefix, while MinIO listings include it, and
+// the case of either depends on the server.
+func lookupMetadata(metadata map[string]string, key string) (string, bool) {
+	for k, v := range metadata {
+		k = strings.TrimPrefix(strings.ToLower(k), "x-amz-meta-")
+		if k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// parseMtime accepts Unix seconds with an optional fractional part, or an
+// RFC 3339 timestamp
+func parseMtime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	secs, frac, _ := strings.Cut(value, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if frac != "" {
+		if strings.Trim(frac, "0123456789") != "" {
+			return time.Time{}, fmt.Errorf("invalid fraction %q", frac)
+		}
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nsec, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
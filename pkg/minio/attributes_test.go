@@ -0,0 +1,157 @@
+package minio
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseMtime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "1714564800", want: time.Unix(1714564800, 0)},
+		{value: "1714564800.5", want: time.Unix(1714564800, 500000000)},
+		{value: "1714564800.123456789", want: time.Unix(1714564800, 123456789)},
+		{value: "1714564800.1234567891", want: time.Unix(1714564800, 123456789)},
+		{value: "1714564800.", want: time.Unix(1714564800, 0)},
+		{value: "2024-05-01T12:00:00Z", want: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01T12:00:00.25+02:00", want: time.Date(2024, 5, 1, 10, 0, 0, 250000000, time.UTC)},
+		{value: "", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: ".5", wantErr: true},
+		{value: "1714564800.-5", wantErr: true},
+		{value: "1714564800.5e3", wantErr: true},
+		{value: "1714564800.5.5", wantErr: true},
+		{value: "2024-05-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMtime(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMtime(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseMtime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     string
+		wantOK   bool
+	}{
+		{name: "stat key", metadata: map[string]string{"Mode": "644"}, want: "644", wantOK: true},
+		{name: "listing key", metadata: map[string]string{"X-Amz-Meta-Mode": "644"}, want: "644", wantOK: true},
+		{name: "lowercase listing key", metadata: map[string]string{"x-amz-meta-mode": "644"}, want: "644", wantOK: true},
+		{name: "missing", metadata: map[string]string{"X-Amz-Meta-Mtime": "1"}},
+		{name: "nil", metadata: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookupMetadata(tt.metadata, metaMode)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("lookupMetadata() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestApplyAttributes(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		metadata  map[string]string
+		wantMode  fs.FileMode
+		wantMtime time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "mode and mtime",
+			metadata:  map[string]string{"X-Amz-Meta-Mode": "755", "X-Amz-Meta-Mtime": "1714564800.5"},
+			wantMode:  0o755,
+			wantMtime: time.Unix(1714564800, 500000000),
+		},
+		{
+			name:      "mode with file type bits",
+			metadata:  map[string]string{"Mode": "100640"},
+			wantMode:  0o640,
+			wantMtime: lastModified,
+		},
+		{
+			name:      "no attributes",
+			metadata:  map[string]string{"Content-Type": "text/plain"},
+			wantMode:  0o600,
+			wantMtime: lastModified,
+		},
+		{name: "mode not octal", metadata: map[string]string{"Mode": "0x1ed"}, wantErr: true},
+		{name: "mode with digit 9", metadata: map[string]string{"Mode": "799"}, wantErr: true},
+		{name: "negative mode", metadata: map[string]string{"Mode": "-644"}, wantErr: true},
+		{name: "empty mode", metadata: map[string]string{"Mode": ""}, wantErr: true},
+		{name: "malformed mtime", metadata: map[string]string{"Mtime": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			object := ObjectInfo{Path: "builds/file", LastModified: lastModified, Metadata: tt.metadata}
+			err := (&Client{}).applyAttributes(context.Background(), object, path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyAttributes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != tt.wantMode {
+				t.Errorf("mode = %v, want %v", info.Mode().Perm(), tt.wantMode)
+			}
+			if !info.ModTime().Equal(tt.wantMtime) {
+				t.Errorf("mtime = %v, want %v", info.ModTime(), tt.wantMtime)
+			}
+		})
+	}
+}
+
+func TestFileAttributesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("data"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Unix(1714564800, 123456789)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attributes := FileAttributes(info)
+	if attributes[metaMode] != "640" {
+		t.Errorf("mode = %q, want %q", attributes[metaMode], "640")
+	}
+	got, err := parseMtime(attributes[metaMtime])
+	if err != nil || !got.Equal(mtime) {
+		t.Errorf("parseMtime(%q) = %v, %v, want %v", attributes[metaMtime], got, err, mtime)
+	}
+}
@@ -0,0 +1,224 @@
+package minio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// blobCache keeps downloaded objects in a directory, addressed by bucket,
+// key and ETag, so unchanged objects are not downloaded again. Files are
+// always copied between the cache and the destination, so that tasks
+// editing a download or preserved attributes never change a cached blob.
+// Every blob has a sidecar with its SHA-256, which is checked on restore.
+type blobCache struct {
+	dir string
+}
+
+// path returns the cache file of an object version
+func (b blobCache) path(bucket, key, etag string) string {
+	sum := sha256.Sum256([]byte(bucket + "\x00" + key + "\x00" + etag))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(b.dir, "blobs", name[:2], name)
+}
+
+// checksum returns the sidecar holding the SHA-256 of a blob
+func checksum(blob string) string {
+	return blob + ".sha256"
+}
+
+// fetch places a copy of the cached object at dest, reporting whether the
+// cache held it. A blob of the wrong size, without a checksum or whose
+// content no longer matches its checksum is removed and treated as missing.
+func (b blobCache) fetch(blob, dest string, size int64) (bool, error) {
+	info, err := os.Stat(blob)
+	if err != nil || info.Size() != size {
+		return false, nil
+	}
+	want, err := os.ReadFile(checksum(blob))
+	if err != nil {
+		return false, nil
+	}
+
+	// Copy next to dest and rename, so dest is never left half-written
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return false, fmt.Errorf("failed to restore %s from cache: %w", dest, err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return false, fmt.Errorf("failed to restore %s from cache: %w", dest, err)
+	}
+
+	sum, err := copyFile(blob, tmp.Name())
+	if err != nil {
+		return false, fmt.Errorf("failed to restore %s from cache: %w", dest, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(want), []byte(sum)) {
+		os.Remove(blob)
+		os.Remove(checksum(blob))
+		return false, fmt.Errorf("cached copy of %s is corrupt", dest)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return false, fmt.Errorf("failed to restore %s from cache: %w", dest, err)
+	}
+
+	// Mark the blob as used, for pruning
+	now := time.Now()
+	os.Chtimes(blob, now, now)
+	return true, nil
+}
+
+// store adds a copy of a downloaded file to the cache. The file is written
+// under a temporary name and renamed, so concurrent builds never see partial
+// blobs.
+func (b blobCache) store(src, blob string) error {
+	if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// The blob is prepared in a directory of its own, as builds sharing the
+	// cache may store the same blob at once
+	dir, err := os.MkdirTemp(filepath.Dir(blob), ".store-*")
+	if err != nil {
+		return fmt.Errorf("failed to cache %s: %w", src, err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, filepath.Base(blob))
+	sum, err := copyFile(src, tmp)
+	if err != nil {
+		return fmt.Errorf("failed to cache %s: %w", src, err)
+	}
+	if err := os.WriteFile(checksum(tmp), []byte(sum+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", src, err)
+	}
+
+	// The checksum goes first, so a blob is never there without one. Blobs
+	// of the same object version have the same content, so a checksum
+	// renamed over by another build still matches.
+	if err := os.Rename(checksum(tmp), checksum(blob)); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", src, err)
+	}
+	if err := os.Rename(tmp, blob); err != nil {
+		return fmt.Errorf("failed to cache %s: %w", src, err)
+	}
+	return nil
+}
+
+// cacheEntry is a blob or an unclaimed partial download that can be pruned
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// prune removes the least recently used blobs and unclaimed partial
+// downloads until the cache holds at most maxSize bytes, returning the
+// number of files and bytes removed. Partial downloads claimed by a
+// running build have a random suffix and are left alone.
+func (b blobCache) prune(maxSize int64) (int, int64, error) {
+	var entries []cacheEntry
+	var total int64
+	for _, sub := range []string{"blobs", "partial"} {
+		err := filepath.WalkDir(filepath.Join(b.dir, sub), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), ".store-") {
+				// A blob being stored by another build
+				return fs.SkipDir
+			}
+			if entry.IsDir() || !isCacheName(entry.Name()) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				// Removed by another build meanwhile
+				return nil
+			}
+			entries = append(entries, cacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to prune cache: %w", err)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, c cacheEntry) int {
+		return a.modTime.Compare(c.modTime)
+	})
+	var removed int
+	var freed int64
+	for _, entry := range entries {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return removed, freed, fmt.Errorf("failed to prune cache: %w", err)
+		}
+		os.Remove(checksum(entry.path))
+		os.Remove(entry.path + ".json")
+		total -= entry.size
+		freed += entry.size
+		removed++
+	}
+	return removed, freed, nil
+}
+
+// isCacheName reports whether name is that of a blob or of an unclaimed
+// partial download, a hex SHA-256 without any suffix
+func isCacheName(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// copyFile copies src to dest and returns the hex SHA-256 of the content
+func copyFile(src, dest string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hash), in); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// linkOrCopy hardlinks src to dest, copying it when they are on different
+// filesystems or links are not supported. Only use it for files that are
+// removed afterwards, as the two names share their content otherwise.
+func linkOrCopy(src, dest string) error {
+	if err := os.Link(src, dest); err == nil {
+		return nil
+	}
+	_, err := copyFile(src, dest)
+	return err
+}
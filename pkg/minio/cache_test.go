@@ -0,0 +1,193 @@
+package minio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storeBlob caches content as the given object version and returns its blob
+func storeBlob(t *testing.T, cache blobCache, key, content string) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blob := cache.path("releases", key, "etag")
+	if err := cache.store(src, blob); err != nil {
+		t.Fatalf("store() = %v", err)
+	}
+	return blob
+}
+
+func TestBlobCacheFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		damage  func(t *testing.T, blob string)
+		wantHit bool
+		wantErr bool
+		gone    bool
+	}{
+		{name: "hit", size: 4, wantHit: true},
+		{name: "wrong size", size: 5},
+		{name: "missing blob", size: 4, damage: func(t *testing.T, blob string) { os.Remove(blob) }},
+		{name: "missing checksum", size: 4, damage: func(t *testing.T, blob string) { os.Remove(checksum(blob)) }},
+		{
+			name: "corrupt content",
+			size: 4,
+			damage: func(t *testing.T, blob string) {
+				if err := os.WriteFile(blob, []byte("DATA"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+			gone:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := blobCache{dir: t.TempDir()}
+			blob := storeBlob(t, cache, "app.tgz", "data")
+			if tt.damage != nil {
+				tt.damage(t, blob)
+			}
+
+			dest := filepath.Join(t.TempDir(), "app.tgz")
+			hit, err := cache.fetch(blob, dest, tt.size)
+			if hit != tt.wantHit || (err != nil) != tt.wantErr {
+				t.Fatalf("fetch() = %v, %v, want %v, error %v", hit, err, tt.wantHit, tt.wantErr)
+			}
+			if _, err := os.Stat(dest); (err == nil) != tt.wantHit {
+				t.Errorf("dest exists = %v, want %v", err == nil, tt.wantHit)
+			}
+			if _, err := os.Stat(blob); tt.gone && !os.IsNotExist(err) {
+				t.Errorf("corrupt blob kept: %v", err)
+			}
+			entries, _ := os.ReadDir(filepath.Dir(dest))
+			if len(entries) > 1 || (len(entries) == 1 && !tt.wantHit) {
+				t.Errorf("temporary files left in destination: %v", entries)
+			}
+		})
+	}
+}
+
+func TestBlobCacheIsolatesCopies(t *testing.T) {
+	cache := blobCache{dir: t.TempDir()}
+	blob := storeBlob(t, cache, "app.tgz", "data")
+
+	dest := filepath.Join(t.TempDir(), "app.tgz")
+	if hit, err := cache.fetch(blob, dest, 4); !hit || err != nil {
+		t.Fatalf("fetch() = %v, %v", hit, err)
+	}
+
+	// Edits and preserved attributes of the restored file leave the blob alone
+	if err := os.WriteFile(dest, []byte("edit"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dest, 0700); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Unix(1714564800, 0)
+	if err := os.Chtimes(dest, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(blob)
+	if err != nil || string(data) != "data" {
+		t.Errorf("blob = %q, %v, want %q", data, err, "data")
+	}
+	info, err := os.Stat(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() == 0700 || info.ModTime().Equal(mtime) {
+		t.Errorf("blob attributes changed: %v %v", info.Mode(), info.ModTime())
+	}
+
+	// Storing the edited file does not change an earlier restore either
+	other := filepath.Join(t.TempDir(), "app.tgz")
+	if hit, err := cache.fetch(blob, other, 4); !hit || err != nil {
+		t.Fatalf("fetch() = %v, %v", hit, err)
+	}
+	if err := cache.store(dest, blob); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(other); string(data) != "data" {
+		t.Errorf("earlier restore = %q, want %q", data, "data")
+	}
+	if info, _ := os.Stat(other); info.Mode().Perm() != 0644 {
+		t.Errorf("restored mode = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestBlobCachePrune(t *testing.T) {
+	cache := blobCache{dir: t.TempDir()}
+	age := func(path string, hours int) {
+		mtime := time.Now().Add(-time.Duration(hours) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldest := storeBlob(t, cache, "oldest", strings.Repeat("a", 100))
+	age(oldest, 3)
+	older := storeBlob(t, cache, "older", strings.Repeat("b", 100))
+	age(older, 2)
+	newest := storeBlob(t, cache, "newest", strings.Repeat("c", 100))
+
+	// An unclaimed partial download and one claimed by a running build
+	shared := cache.partialPath("releases", "partial")
+	claimed := shared + ".123456"
+	if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{shared, shared + ".json", claimed, claimed + ".json"} {
+		if err := os.WriteFile(path, []byte(strings.Repeat("d", 100)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	age(shared, 1)
+	age(claimed, 10)
+
+	// A blob still being stored by another build
+	storing := filepath.Join(filepath.Dir(oldest), ".store-1", filepath.Base(oldest))
+	if err := os.MkdirAll(filepath.Dir(storing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storing, []byte("e"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	age(storing, 10)
+
+	removed, freed, err := cache.prune(250)
+	if err != nil || removed != 2 || freed != 200 {
+		t.Fatalf("prune() = %d, %d, %v, want 2, 200", removed, freed, err)
+	}
+
+	for path, want := range map[string]bool{
+		oldest:            false,
+		checksum(oldest):  false,
+		older:             false,
+		checksum(older):   false,
+		shared:            true,
+		shared + ".json":  true,
+		newest:            true,
+		checksum(newest):  true,
+		claimed:           true,
+		claimed + ".json": true,
+		storing:           true,
+	} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", path, err == nil, want)
+		}
+	}
+
+	// Within the limit nothing is removed
+	if removed, _, err := cache.prune(1 << 20); err != nil || removed != 0 {
+		t.Errorf("prune() = %d, %v, want nothing removed", removed, err)
+	}
+}
@@ -3,18 +3,23 @@ package minio
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/zinc-sig/minio-resource/pkg/logging"
 	"github.com/zinc-sig/minio-resource/pkg/models"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
 )
 
 // Client wraps the Minio client with helper methods
@@ -22,10 +27,17 @@ type Client struct {
 	client     *minio.Client
 	bucket     string
 	pathPrefix string
+	logger     *slog.Logger
+	limits     *limits
 }
 
-// NewClient creates a new Minio client from the provided source configuration
-func NewClient(source models.Source) (*Client, error) {
+// NewClient creates a new Minio client from the provided source
+// configuration, logging to logger if it is not nil
+func NewClient(source models.Source, logger *slog.Logger) (*Client, error) {
+	if logger == nil {
+		logger = logging.Discard()
+	}
+
 	// Create Minio client options
 	opts := &minio.Options{
 		Creds:  credentials.NewStaticV4(source.AccessKey, source.SecretKey, ""),
@@ -34,14 +46,21 @@ func NewClient(source models.Source) (*Client, error) {
 	}
 
 	// Configure SSL verification
+	transport, err := minio.DefaultTransport(source.UseSSLValue())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
 	if source.SkipSSLVerification && source.UseSSLValue() {
-		opts.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
 		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
 	}
 
+	// Apply bandwidth and request limits to every request
+	limits := newLimits()
+	opts.Transport = &limitedTransport{base: transport, limits: limits}
+
 	// Create the client
 	minioClient, err := minio.New(source.Endpoint, opts)
 	if err != nil {
@@ -58,28 +77,53 @@ func NewClient(source models.Source) (*Client, error) {
 		client:     minioClient,
 		bucket:     source.Bucket,
 		pathPrefix: pathPrefix,
+		logger:     logger,
+		limits:     limits,
 	}, nil
 }
 
+// Logger returns the logger the client writes to
+func (c *Client) Logger() *slog.Logger {
+	return c.logger
+}
+
 // ObjectInfo contains information about an object in the bucket
 type ObjectInfo struct {
 	Path         string
 	ETag         string
 	LastModified time.Time
 	Size         int64
+	// Metadata is the user metadata, if the listing included it
+	Metadata map[string]string
 }
 
-// ListObjects lists all objects in the bucket with the configured path prefix
-func (c *Client) ListObjects(ctx context.Context) ([]ObjectInfo, error) {
-	opts := minio.ListObjectsOptions{
-		Prefix:    c.pathPrefix,
-		Recursive: true,
+// ErrStopListing can be returned by a WalkObjects callback to stop the
+// listing early without reporting an error
+var ErrStopListing = errors.New("stop listing")
+
+// ListOptions narrows down an object listing
+type ListOptions struct {
+	// StartAfter lists only keys that sort lexically after this key
+	StartAfter string
+	// Prefix lists only keys starting with it, relative to the path prefix
+	Prefix string
+	// WithMetadata includes user metadata, where the server supports it
+	WithMetadata bool
+}
+
+// WalkObjects streams all objects with the configured path prefix to fn in
+// lexical key order, without buffering the listing in memory
+func (c *Client) WalkObjects(ctx context.Context, opts ListOptions, fn func(ObjectInfo) error) error {
+	listOpts := minio.ListObjectsOptions{
+		Prefix:       c.pathPrefix + opts.Prefix,
+		Recursive:    true,
+		StartAfter:   opts.StartAfter,
+		WithMetadata: opts.WithMetadata,
 	}
 
-	var objects []ObjectInfo
-	for object := range c.client.ListObjects(ctx, c.bucket, opts) {
+	for object := range c.client.ListObjectsIter(ctx, c.bucket, listOpts) {
 		if object.Err != nil {
-			return nil, fmt.Errorf("error listing objects: %w", object.Err)
+			return fmt.Errorf("error listing objects: %w", object.Err)
 		}
 
 		// Skip directories (they have size 0 and end with /)
@@ -87,17 +131,51 @@ func (c *Client) ListObjects(ctx context.Context) ([]ObjectInfo, error) {
 			continue
 		}
 
-		objects = append(objects, ObjectInfo{
-			Path:         object.Key,
-			ETag:         object.ETag,
-			LastModified: object.LastModified,
-			Size:         object.Size,
-		})
+		if err := fn(objectInfo(object)); err != nil {
+			if errors.Is(err, ErrStopListing) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListObjects lists all objects in the bucket with the configured path prefix
+func (c *Client) ListObjects(ctx context.Context) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := c.WalkObjects(ctx, ListOptions{}, func(object ObjectInfo) error {
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return objects, nil
 }
 
+// StatObject returns information about a single object in the bucket
+func (c *Client) StatObject(ctx context.Context, objectPath string) (ObjectInfo, error) {
+	object, err := c.client.StatObject(ctx, c.bucket, objectPath, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat object %s: %w", objectPath, err)
+	}
+	return objectInfo(object), nil
+}
+
+// objectInfo converts a minio object listing entry to an ObjectInfo
+func objectInfo(object minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Path:         object.Key,
+		ETag:         object.ETag,
+		LastModified: object.LastModified,
+		Size:         object.Size,
+		Metadata:     object.UserMetadata,
+	}
+}
+
 // GetObject downloads a single object from the bucket
 func (c *Client) GetObject(ctx context.Context, objectPath string) (io.ReadCloser, error) {
 	object, err := c.client.GetObject(ctx, c.bucket, objectPath, minio.GetObjectOptions{})
@@ -109,105 +187,560 @@ func (c *Client) GetObject(ctx context.Context, objectPath string) (io.ReadClose
 
 // DownloadResult contains the result of a download operation
 type DownloadResult struct {
-	Path  string
-	Error error
+	Path      string
+	LocalPath string
+	Size      int64
+	Error     error
+	// Retried holds the errors of attempts that were retried
+	Retried []error
+	// Cached is set when the file came from the cache directory
+	Cached bool
+	// Metadata describes the object when requested with
+	// DownloadOptions.Describe
+	Metadata *models.ObjectMetadata
 }
 
-// DownloadAllObjects downloads all objects with the configured path prefix to the destination directory
-func (c *Client) DownloadAllObjects(ctx context.Context, destDir string, parallel int) ([]DownloadResult, error) {
-	// List all objects first
-	objects, err := c.ListObjects(ctx)
-	for _, object := range objects {
-		if err != nil {
-			return nil, err
+// DownloadOptions configures DownloadAllObjects
+type DownloadOptions struct {
+	// Parallel is the number of concurrent downloads, defaulting to 5. With
+	// AutoParallel it is the most concurrency may grow to, defaulting to 64.
+	Parallel int
+	// AutoParallel adjusts concurrency to the observed throughput, object
+	// sizes and throttling responses
+	AutoParallel bool
+	// Progress configures periodic progress reports
+	Progress progress.Options
+	// Retries is how often a failed download is retried
+	Retries int
+	// CacheDir, if set, keeps downloaded objects by bucket, key and ETag
+	// so that unchanged objects are not downloaded again. CacheMaxSize, if
+	// positive, is the size the cache is pruned to after the downloads, by
+	// removing the least recently used objects.
+	CacheDir     string
+	CacheMaxSize int64
+	// RangeThreshold is the size from which objects are downloaded as
+	// concurrent ranged requests of RangeSize bytes, sharing the Parallel
+	// budget. Zero disables ranged downloads.
+	RangeThreshold int64
+	RangeSize      int64
+	// PreserveAttributes sets the mode and modification time of downloaded
+	// files from the object's metadata, see FileAttributes
+	PreserveAttributes bool
+	// Describe fills DownloadResult.Metadata, see DescribeObject
+	Describe bool
+	// Prefix downloads only keys starting with it, relative to the path
+	// prefix, and Filter, if set, skips the listed objects it rejects
+	Prefix string
+	Filter func(ObjectInfo) bool
+	// Flatten writes every object to the destination directory itself,
+	// dropping the directories of its key. Objects whose names collide
+	// fail the download.
+	Flatten bool
+}
+
+// DownloadAllObjects downloads all objects with the configured path prefix
+// to the destination directory. A fixed pool of workers downloads objects
+// while they are still being listed, so large prefixes are neither held in
+// memory nor wait for the listing to finish. Results are sorted by path.
+func (c *Client) DownloadAllObjects(ctx context.Context, destDir string, opts DownloadOptions) ([]DownloadResult, error) {
+	workers := opts.Parallel
+	if workers <= 0 {
+		workers = defaultParallel
+		if opts.AutoParallel {
+			workers = maxAutoParallel
 		}
-		fmt.Fprintf(os.Stderr, "attempting to download: %s\n", object.Path)
 	}
 
-	if parallel <= 0 {
-		parallel = 5 // Default parallelism
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tracker := progress.Start(c.logger, "Downloading", 0, 0, opts.Progress)
+	defer tracker.Stop()
+
+	d := &download{
+		destDir: destDir,
+		opts:    opts,
+		slots:   newBudget(workers),
+		tracker: tracker,
+	}
+	if opts.CacheDir != "" {
+		d.cache = &blobCache{dir: opts.CacheDir}
 	}
 
-	// Create a semaphore for controlling parallelism
-	sem := make(chan struct{}, parallel)
-	var wg sync.WaitGroup
-	results := make([]DownloadResult, len(objects))
+	// With automatic parallelism, all workers exist but only as many as
+	// the budget allows download at once
+	if opts.AutoParallel {
+		tuner := newAutoTuner(d.slots, workers, tracker, &c.limits.throttled, c.logger)
+		go tuner.run(ctx)
+	}
 
-	for i, obj := range objects {
+	// Start the workers
+	jobs := make(chan ObjectInfo)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []DownloadResult
+	)
+	for range workers {
 		wg.Add(1)
-		go func(idx int, object ObjectInfo) {
+		go func() {
 			defer wg.Done()
+			for object := range jobs {
+				result := c.downloadOne(ctx, d, object)
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
 
-			sem <- struct{}{}        // Acquire semaphore
-			defer func() { <-sem }() // Release semaphore
+	// Feed them from the listing
+	var count int
+	var total int64
+	var flattened map[string]string
+	if opts.Flatten {
+		flattened = make(map[string]string)
+	}
+	listOpts := ListOptions{Prefix: opts.Prefix, WithMetadata: opts.PreserveAttributes}
+	err := c.WalkObjects(ctx, listOpts, func(object ObjectInfo) error {
+		if opts.Filter != nil && !opts.Filter(object) {
+			return nil
+		}
+		if flattened != nil {
+			name := filepath.Base(object.Path)
+			if other, ok := flattened[name]; ok {
+				return fmt.Errorf("cannot flatten %s and %s, both would be written to %s", other, object.Path, name)
+			}
+			flattened[name] = object.Path
+		}
+		count++
+		total += object.Size
+		tracker.Expect(1, object.Size)
+		select {
+		case jobs <- object:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(jobs)
+	if err != nil {
+		cancel()
+	} else {
+		c.logger.Info("Listed objects", "count", count, "size", humanize.IBytes(uint64(total)))
+	}
+	wg.Wait()
 
-			result := DownloadResult{Path: object.Path}
+	if d.cache != nil && opts.CacheMaxSize > 0 {
+		removed, freed, pruneErr := d.cache.prune(opts.CacheMaxSize)
+		if pruneErr != nil {
+			c.logger.Warn("Failed to prune cache", "error", pruneErr)
+		}
+		if removed > 0 {
+			c.logger.Debug("Pruned cache", "files", removed, "size", humanize.IBytes(uint64(freed)))
+		}
+	}
 
-			// Calculate local path by removing the prefix
-			localPath := strings.TrimPrefix(object.Path, c.pathPrefix)
-			if localPath == "" {
-				localPath = filepath.Base(object.Path)
-			}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(results, func(a, b DownloadResult) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return results, nil
+}
 
-			fullPath := filepath.Join(destDir, localPath)
+// download is the state shared by the workers of DownloadAllObjects
+type download struct {
+	destDir string
+	opts    DownloadOptions
+	cache   *blobCache
+	slots   *budget
+	tracker *progress.Tracker
+}
 
-			// Create directory if needed
-			dir := filepath.Dir(fullPath)
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				result.Error = fmt.Errorf("failed to create directory %s: %w", dir, err)
-				results[idx] = result
-				return
-			}
+// downloadOne downloads a single listed object, from the cache if it holds
+// the object, and in ranges if it is large
+func (c *Client) downloadOne(ctx context.Context, d *download, object ObjectInfo) DownloadResult {
+	localPath := c.LocalPath(object.Path)
+	if d.opts.Flatten {
+		localPath = filepath.Base(localPath)
+	}
+	result := DownloadResult{Path: object.Path, LocalPath: localPath, Size: object.Size}
+	defer d.tracker.FileDone()
 
-			// Download the object
-			if err := c.downloadObject(ctx, object.Path, fullPath); err != nil {
-				result.Error = err
+	if err := d.slots.acquire(ctx); err != nil {
+		result.Error = err
+		return result
+	}
+	defer d.slots.release()
+	fullPath := filepath.Join(d.destDir, localPath)
+
+	// Create directory if needed
+	dir := filepath.Dir(fullPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		result.Error = fmt.Errorf("failed to create directory %s: %w", dir, err)
+		return result
+	}
+
+	// Use the cached copy of unchanged objects
+	var blob string
+	if d.cache != nil && object.ETag != "" {
+		blob = d.cache.path(c.bucket, object.Path, object.ETag)
+		hit, err := d.cache.fetch(blob, fullPath, object.Size)
+		if err != nil {
+			c.logger.Warn("Cache unusable, downloading", "key", object.Path, "error", err)
+		}
+		if hit {
+			c.logger.Debug("Restored from cache", "key", object.Path)
+			d.tracker.Add(object.Size)
+			result.Cached = true
+			c.completeDownload(ctx, d, object, fullPath, &result)
+			return result
+		}
+	}
+
+	// Download the object, in ranges if it is large. Partial downloads are
+	// kept in the cache so later runs can resume them, and next to the
+	// destination otherwise.
+	var rangeSize int64
+	if d.opts.RangeThreshold > 0 && d.opts.RangeSize > 0 && object.Size >= d.opts.RangeThreshold {
+		rangeSize = d.opts.RangeSize
+	}
+	var (
+		part *partial
+		err  error
+	)
+	if d.cache != nil {
+		part, err = claimPartial(d.cache.partialPath(c.bucket, object.Path), object, rangeSize)
+	} else {
+		part, err = openPartial(filepath.Join(dir, "."+filepath.Base(fullPath)+".part"), object, rangeSize)
+	}
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	attempt := func() error {
+		if rangeSize > 0 {
+			return c.downloadRanges(ctx, object, fullPath, rangeSize, part, d.slots, d.tracker)
+		}
+		return c.downloadObject(ctx, object, fullPath, part, d.tracker)
+	}
+
+	c.logger.Debug("Downloading", "key", object.Path, "size", object.Size)
+	start := time.Now()
+	retried, err := c.Retry(ctx, d.opts.Retries, object.Path, attempt)
+	result.Retried = retried
+	if err != nil {
+		result.Error = err
+		part.abandon()
+	} else {
+		c.logger.Debug("Downloaded", "key", object.Path, "duration", time.Since(start))
+		if blob != "" {
+			if err := d.cache.store(fullPath, blob); err != nil {
+				c.logger.Warn("Failed to cache object", "key", object.Path, "error", err)
 			}
+		}
+		c.completeDownload(ctx, d, object, fullPath, &result)
+	}
+
+	return result
+}
 
-			results[idx] = result
-		}(i, obj)
+// completeDownload describes the object and applies its attributes to the
+// downloaded file, as requested. Failing to do so leaves the file usable,
+// so it is only logged.
+func (c *Client) completeDownload(ctx context.Context, d *download, object ObjectInfo, path string, result *DownloadResult) {
+	if d.opts.Describe {
+		metadata, err := c.DescribeObject(ctx, object.Path)
+		if err != nil {
+			c.logger.Warn("Failed to describe object", "key", object.Path, "error", err)
+		} else {
+			metadata.Path = result.LocalPath
+			result.Metadata = &metadata
+			if object.Metadata == nil {
+				object.Metadata = metadata.UserMetadata
+			}
+		}
 	}
 
-	wg.Wait()
-	return results, nil
+	if d.opts.PreserveAttributes {
+		if err := c.applyAttributes(ctx, object, path); err != nil {
+			c.logger.Warn("Failed to preserve attributes", "key", object.Path, "error", err)
+		}
+	}
 }
 
-// downloadObject downloads a single object to a file
-func (c *Client) downloadObject(ctx context.Context, objectPath, destPath string) error {
-	// Get the object
-	object, err := c.client.GetObject(ctx, c.bucket, objectPath, minio.GetObjectOptions{})
+// DescribeObject returns the metadata of an object, including its tags and
+// checksums
+func (c *Client) DescribeObject(ctx context.Context, objectPath string) (models.ObjectMetadata, error) {
+	info, err := c.client.StatObject(ctx, c.bucket, objectPath, minio.StatObjectOptions{Checksum: true})
 	if err != nil {
-		return fmt.Errorf("failed to get object %s: %w", objectPath, err)
+		return models.ObjectMetadata{}, fmt.Errorf("failed to stat object %s: %w", objectPath, err)
+	}
+
+	metadata := models.ObjectMetadata{
+		Key:          info.Key,
+		ETag:         info.ETag,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+		UserMetadata: info.UserMetadata,
+		VersionID:    info.VersionID,
+		StorageClass: info.StorageClass,
 	}
-	defer object.Close()
 
-	// Create the destination file
-	file, err := os.Create(destPath)
+	checksums := map[string]string{
+		"crc32":     info.ChecksumCRC32,
+		"crc32c":    info.ChecksumCRC32C,
+		"crc64nvme": info.ChecksumCRC64NVME,
+		"sha1":      info.ChecksumSHA1,
+		"sha256":    info.ChecksumSHA256,
+	}
+	for name, value := range checksums {
+		if value == "" {
+			delete(checksums, name)
+		}
+	}
+	if len(checksums) > 0 {
+		metadata.Checksums = checksums
+	}
+
+	// Tags need a request of their own, which is skipped for untagged
+	// objects
+	if info.UserTagCount > 0 {
+		tags, err := c.client.GetObjectTagging(ctx, c.bucket, objectPath, minio.GetObjectTaggingOptions{})
+		if err != nil {
+			return models.ObjectMetadata{}, fmt.Errorf("failed to get tags of object %s: %w", objectPath, err)
+		}
+		metadata.Tags = tags.ToMap()
+	}
+
+	return metadata, nil
+}
+
+// LocalPath returns the path an object is downloaded to, relative to the
+// destination directory, by removing the path prefix
+func (c *Client) LocalPath(objectPath string) string {
+	localPath := strings.TrimPrefix(objectPath, c.pathPrefix)
+	if localPath == "" {
+		localPath = filepath.Base(objectPath)
+	}
+	return localPath
+}
+
+// downloadObject downloads a single object to destPath through its part
+// file, continuing after the bytes a failed attempt already wrote
+func (c *Client) downloadObject(ctx context.Context, object ObjectInfo, destPath string, part *partial, tracker *progress.Tracker) error {
+	// Open the part file at the end of the bytes already written
+	offset := part.offset()
+	file, err := os.OpenFile(part.path, os.O_WRONLY|os.O_CREATE, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", destPath, err)
+		return fmt.Errorf("failed to create file %s: %w", part.path, err)
 	}
 	defer file.Close()
+	if err := file.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to resume file %s: %w", part.path, err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to resume file %s: %w", part.path, err)
+	}
+	tracker.Add(offset)
 
-	// Copy the content
-	_, err = io.Copy(file, object)
+	// Copy the content, taking back the progress of a failed attempt
+	written, err := c.copyObject(ctx, object, file, offset, tracker)
 	if err != nil {
-		return fmt.Errorf("failed to write file %s: %w", destPath, err)
+		tracker.Add(-offset - written)
+		return err
 	}
 
-	return nil
+	if err := file.Close(); err != nil {
+		tracker.Add(-offset - written)
+		return fmt.Errorf("failed to write file %s: %w", part.path, err)
+	}
+	return part.finish(destPath)
 }
 
-// PutObject uploads an object to the bucket
-func (c *Client) PutObject(ctx context.Context, objectPath string, reader io.Reader, size int64, contentType string) error {
-	opts := minio.PutObjectOptions{
-		ContentType: contentType,
+// copyObject writes the object from offset on to w, returning the number of
+// bytes written. The download is pinned to the listed ETag, so the part file
+// only ever holds the version its sidecar records and the remainder of a
+// changed object fails instead of mixing versions.
+func (c *Client) copyObject(ctx context.Context, object ObjectInfo, w io.Writer, offset int64, tracker *progress.Tracker) (int64, error) {
+	if offset >= object.Size && object.Size > 0 {
+		return 0, nil
 	}
 
-	_, err := c.client.PutObject(ctx, c.bucket, objectPath, reader, size, opts)
+	opts := minio.GetObjectOptions{}
+	if object.ETag != "" {
+		if err := opts.SetMatchETag(object.ETag); err != nil {
+			return 0, err
+		}
+	}
+	if offset > 0 {
+		c.logger.Info("Resuming download", "key", object.Path, "offset", humanize.IBytes(uint64(offset)))
+		if err := opts.SetRange(offset, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	reader, err := c.client.GetObject(ctx, c.bucket, object.Path, opts)
 	if err != nil {
-		return fmt.Errorf("failed to put object %s: %w", objectPath, err)
+		return 0, fmt.Errorf("failed to get object %s: %w", object.Path, err)
 	}
+	defer reader.Close()
 
+	written, err := io.Copy(w, tracker.Reader(reader))
+	if err != nil {
+		return written, fmt.Errorf("failed to download %s: %w", object.Path, err)
+	}
+	return written, nil
+}
+
+// PresignGetObject returns a URL that downloads the object without
+// credentials until it expires
+func (c *Client) PresignGetObject(ctx context.Context, objectPath string, expires time.Duration) (string, error) {
+	u, err := c.client.PresignedGetObject(ctx, c.bucket, objectPath, expires, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", objectPath, err)
+	}
+	return u.String(), nil
+}
+
+// PutOptions configures PutObject
+type PutOptions struct {
+	ContentType string
+	// Progress, if set, is read from as the object is uploaded, see
+	// progress.Tracker.Counter
+	Progress io.Reader
+	// PartSize is the size of the parts of a multipart upload. Zero picks
+	// one from the object size.
+	PartSize int64
+	// Threads is the number of parts uploaded at once. Streams of unknown
+	// size buffer this many parts in memory.
+	Threads int
+	// DisableMultipart uploads with a single request, which limits objects
+	// to 5 GiB and requires a known size
+	DisableMultipart bool
+	// UserMetadata is stored with the object, e.g. from FileAttributes
+	UserMetadata map[string]string
+}
+
+// PutObject uploads an object to the bucket and returns its size. A size of
+// -1 streams the reader until EOF in parts, for pipes and generated content.
+func (c *Client) PutObject(ctx context.Context, objectPath string, reader io.Reader, size int64, opts PutOptions) (int64, error) {
+	putOpts := minio.PutObjectOptions{
+		ContentType:      opts.ContentType,
+		Progress:         opts.Progress,
+		PartSize:         uint64(opts.PartSize),
+		DisableMultipart: opts.DisableMultipart,
+		UserMetadata:     opts.UserMetadata,
+	}
+	if opts.Threads > 0 {
+		putOpts.NumThreads = uint(opts.Threads)
+		putOpts.ConcurrentStreamParts = size < 0 && opts.Threads > 1
+	}
+
+	ctx, uploads := recordUploads(ctx)
+	info, err := c.client.PutObject(ctx, c.bucket, objectPath, reader, size, putOpts)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.abortUploads(c.bucket, objectPath, uploads)
+		}
+		return 0, fmt.Errorf("failed to put object %s: %w", objectPath, err)
+	}
+
+	return info.Size, nil
+}
+
+// CopySource identifies the object copied by CopyObject. Bucket defaults to
+// the configured bucket, and a non-empty ETag makes the copy fail if the
+// object has changed.
+type CopySource struct {
+	Bucket string
+	Key    string
+	ETag   string
+}
+
+// maxSingleCopy is the largest object S3 copies with a single request
+const maxSingleCopy = 5 << 30
+
+// CopyObject copies an object server-side to dstKey in dstBucket and returns
+// the new object. Objects up to 5 GiB are copied with a single request, in
+// which the server carries over the content type, user metadata and tags and
+// keeps the ETag of simple uploads. Larger objects are copied in parts, with
+// the metadata and tags set explicitly.
+func (c *Client) CopyObject(ctx context.Context, src CopySource, dstBucket, dstKey string) (ObjectInfo, error) {
+	if src.Bucket == "" {
+		src.Bucket = c.bucket
+	}
+	name := src.Bucket + "/" + src.Key
+
+	stat, err := c.client.StatObject(ctx, src.Bucket, src.Key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat object %s: %w", name, err)
+	}
+	if src.ETag != "" && strings.Trim(src.ETag, `"`) != strings.Trim(stat.ETag, `"`) {
+		return ObjectInfo{}, fmt.Errorf("object %s has changed: etag is %s, expected %s", name, stat.ETag, src.ETag)
+	}
+
+	source := minio.CopySrcOptions{
+		Bucket:    src.Bucket,
+		Object:    src.Key,
+		MatchETag: stat.ETag,
+	}
+	dst := minio.CopyDestOptions{
+		Bucket: dstBucket,
+		Object: dstKey,
+	}
+
+	var info minio.UploadInfo
+	if stat.Size <= maxSingleCopy {
+		info, err = c.client.CopyObject(ctx, dst, source)
+	} else {
+		tags, tagErr := c.client.GetObjectTagging(ctx, src.Bucket, src.Key, minio.GetObjectTaggingOptions{})
+		if tagErr != nil {
+			return ObjectInfo{}, fmt.Errorf("failed to get tags of object %s: %w", name, tagErr)
+		}
+
+		// Multipart copies do not carry anything over, so the metadata is set
+		// explicitly. Standard headers in UserMetadata are sent as headers.
+		metadata := make(map[string]string, len(stat.UserMetadata)+5)
+		for key, value := range stat.UserMetadata {
+			metadata[key] = value
+		}
+		for _, header := range []string{"Content-Type", "Content-Encoding", "Content-Disposition", "Content-Language", "Cache-Control"} {
+			if value := stat.Metadata.Get(header); value != "" {
+				metadata[header] = value
+			}
+		}
+		dst.UserMetadata = metadata
+		dst.ReplaceMetadata = true
+		dst.UserTags = tags.ToMap()
+		dst.ReplaceTags = true
+
+		composeCtx, uploads := recordUploads(ctx)
+		info, err = c.client.ComposeObject(composeCtx, dst, source)
+		if err != nil && ctx.Err() != nil {
+			c.abortUploads(dstBucket, dstKey, uploads)
+		}
+	}
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to copy object %s to %s/%s: %w", name, dstBucket, dstKey, err)
+	}
+
+	return ObjectInfo{
+		Path:         info.Key,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Size:         stat.Size,
+	}, nil
+}
+
+// RemoveObject deletes an object from the bucket
+func (c *Client) RemoveObject(ctx context.Context, objectPath string) error {
+	if err := c.client.RemoveObject(ctx, c.bucket, objectPath, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to remove object %s: %w", objectPath, err)
+	}
 	return nil
 }
 
@@ -0,0 +1,121 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zinc-sig/minio-resource/pkg/logging"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+// objectServer serves a single object and records the conditional and
+// range headers of the requests for it
+type objectServer struct {
+	content string
+	etag    string
+
+	mu       sync.Mutex
+	ifMatch  []string
+	rangeHdr []string
+}
+
+func (s *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ifMatch = append(s.ifMatch, r.Header.Get("If-Match"))
+	s.rangeHdr = append(s.rangeHdr, r.Header.Get("Range"))
+	s.mu.Unlock()
+
+	if match := r.Header.Get("If-Match"); match != "" && strings.Trim(match, `"`) != s.etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	content, status := s.content, http.StatusOK
+	if spec, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+		first, last, _ := strings.Cut(spec, "-")
+		start, _ := strconv.Atoi(first)
+		end := len(s.content) - 1
+		if last != "" {
+			end, _ = strconv.Atoi(last)
+		}
+		content, status = s.content[start:end+1], http.StatusPartialContent
+		w.Header().Set("Content-Range", "bytes "+first+"-"+strconv.Itoa(end)+"/"+strconv.Itoa(len(s.content)))
+	}
+	w.Header().Set("ETag", `"`+s.etag+`"`)
+	w.Header().Set("Last-Modified", "Wed, 01 May 2024 12:00:00 GMT")
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(status)
+	w.Write([]byte(content))
+}
+
+// newTestClient returns a client for the bucket served by handler
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	useSSL := false
+	client, err := NewClient(models.Source{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		AccessKey: "access",
+		SecretKey: "secret",
+		Bucket:    "releases",
+		Region:    "us-east-1",
+		UseSSL:    &useSSL,
+	}, logging.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCopyObjectPinsETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		listedETag  string
+		offset      int64
+		wantIfMatch string
+		wantRange   string
+		want        string
+		wantErr     bool
+	}{
+		{name: "from the start", listedETag: "v1", wantIfMatch: `"v1"`, want: "abcdefgh"},
+		{name: "resumed", listedETag: "v1", offset: 3, wantIfMatch: `"v1"`, wantRange: "bytes=3-", want: "defgh"},
+		{name: "changed since listing", listedETag: "v0", wantIfMatch: `"v0"`, wantErr: true},
+		{name: "changed since listing when resumed", listedETag: "v0", offset: 3, wantIfMatch: `"v0"`, wantRange: "bytes=3-", wantErr: true},
+		{name: "without etag", want: "abcdefgh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &objectServer{content: "abcdefgh", etag: "v1"}
+			client := newTestClient(t, server)
+			tracker := progress.Start(logging.Discard(), "Downloading", 0, 0, progress.Options{})
+
+			var buf bytes.Buffer
+			object := ObjectInfo{Path: "app.tgz", ETag: tt.listedETag, Size: 8}
+			_, err := client.copyObject(context.Background(), object, &buf, tt.offset, tracker)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("copyObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("copyObject() wrote %q, want %q", buf.String(), tt.want)
+			}
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			if len(server.ifMatch) == 0 {
+				t.Fatal("no request made")
+			}
+			if server.ifMatch[0] != tt.wantIfMatch || server.rangeHdr[0] != tt.wantRange {
+				t.Errorf("If-Match = %q, Range = %q, want %q, %q", server.ifMatch[0], server.rangeHdr[0], tt.wantIfMatch, tt.wantRange)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package minio
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// Bounds of the bandwidth token bucket. Reads are split into chunks of at
+// most the burst, which is a tenth of a second of bandwidth.
+const (
+	minBandwidthBurst = 4 << 10
+	maxBandwidthBurst = 4 << 20
+)
+
+// limits are token buckets shared by every request of a client, so that
+// the caps hold across all concurrent transfers. They are unlimited until
+// SetLimits is called. Throttled counts the responses in which the server
+// asked to slow down.
+type limits struct {
+	bandwidth *rate.Limiter
+	requests  *rate.Limiter
+	throttled atomic.Int64
+}
+
+func newLimits() *limits {
+	return &limits{
+		bandwidth: rate.NewLimiter(rate.Inf, 0),
+		requests:  rate.NewLimiter(rate.Inf, 0),
+	}
+}
+
+// SetLimits caps the bytes per second sent and received, and the number of
+// requests started per second, across all transfers of the client. Zero
+// leaves a limit off.
+func (c *Client) SetLimits(bytesPerSecond int64, requestsPerSecond int) {
+	if bytesPerSecond > 0 {
+		c.limits.bandwidth.SetBurst(int(min(max(bytesPerSecond/10, minBandwidthBurst), maxBandwidthBurst)))
+		c.limits.bandwidth.SetLimit(rate.Limit(bytesPerSecond))
+	}
+	if requestsPerSecond > 0 {
+		c.limits.requests.SetBurst(1)
+		c.limits.requests.SetLimit(rate.Limit(requestsPerSecond))
+	}
+	if bytesPerSecond > 0 || requestsPerSecond > 0 {
+		c.logger.Debug("Limiting transfers", "bytes_per_second", bytesPerSecond, "requests_per_second", requestsPerSecond)
+	}
+}
+
+// limitedTransport applies the limits to every request and to the bodies
+// sent and received, which covers downloads, uploads and ranged requests
+// alike, counts throttling responses and records multipart uploads for
+// abortUploads
+type limitedTransport struct {
+	base   http.RoundTripper
+	limits *limits
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.limits.requests.Limit() != rate.Inf {
+		if err := t.limits.requests.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	bandwidth := t.limits.bandwidth
+	limited := bandwidth.Limit() != rate.Inf
+	if limited && req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(ctx)
+		req.Body = &limitedBody{ReadCloser: req.Body, ctx: ctx, limiter: bandwidth}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests {
+		t.limits.throttled.Add(1)
+	}
+	if recorder, ok := ctx.Value(uploadRecorderKey{}).(*uploadRecorder); ok {
+		recorder.observe(req, resp)
+	}
+	if limited {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, ctx: ctx, limiter: bandwidth}
+	}
+	return resp, nil
+}
+
+// limitedBody waits for bandwidth tokens for every chunk read
+type limitedBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if burst := b.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.WaitN(b.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,193 @@
+package minio
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+// Parallelism of downloads
+const (
+	defaultParallel = 5
+	maxAutoParallel = 64
+	// startAutoParallel is where automatic parallelism begins before it
+	// has observed any throughput
+	startAutoParallel = 4
+	tuneInterval      = 2 * time.Second
+	// probeAfter is the number of intervals automatic parallelism holds
+	// steady before it tries more concurrency again
+	probeAfter = 5
+	// smallObject is the average size below which transfers are dominated
+	// by request latency, so concurrency is grown faster
+	smallObject = 1 << 20
+)
+
+// budget limits the number of concurrent requests of a download. Every
+// object holds one slot while it downloads, and large objects take more
+// for ranged requests only when they are free, so a few big files cannot
+// starve the others. The size can change while slots are held; shrinking
+// takes effect as slots are released.
+type budget struct {
+	mu   sync.Mutex
+	size int
+	used int
+	wake chan struct{}
+}
+
+func newBudget(size int) *budget {
+	return &budget{size: size, wake: make(chan struct{})}
+}
+
+// acquire waits for a free slot
+func (b *budget) acquire(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.used < b.size {
+			b.used++
+			b.mu.Unlock()
+			return nil
+		}
+		wake := b.wake
+		b.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tryAcquire takes a free slot without waiting, reporting whether it did
+func (b *budget) tryAcquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used < b.size {
+		b.used++
+		return true
+	}
+	return false
+}
+
+func (b *budget) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used--
+	b.broadcast()
+}
+
+// resize changes the number of slots
+func (b *budget) resize(size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.size = size
+	b.broadcast()
+}
+
+// broadcast wakes all waiters; the caller holds mu
+func (b *budget) broadcast() {
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
+
+// autoTuner adjusts the size of a budget to the throughput it observes.
+// It grows concurrency while that raises the rate of bytes or files,
+// doubling it for small objects whose transfers are dominated by latency,
+// steps back when growing did not help and halves it as soon as the server
+// answers with SlowDown or another throttling response.
+type autoTuner struct {
+	slots     *budget
+	max       int
+	tracker   *progress.Tracker
+	throttled *atomic.Int64
+	logger    *slog.Logger
+
+	current       int
+	step          int
+	grew          bool
+	holds         int
+	bytes, files  int64
+	bytesRate     float64
+	filesRate     float64
+	lastThrottled int64
+}
+
+func newAutoTuner(slots *budget, max int, tracker *progress.Tracker, throttled *atomic.Int64, logger *slog.Logger) *autoTuner {
+	current := min(startAutoParallel, max)
+	slots.resize(current)
+	return &autoTuner{
+		slots:         slots,
+		max:           max,
+		tracker:       tracker,
+		throttled:     throttled,
+		logger:        logger,
+		current:       current,
+		lastThrottled: throttled.Load(),
+	}
+}
+
+// run adjusts the budget every tuneInterval until ctx is done
+func (a *autoTuner) run(ctx context.Context) {
+	ticker := time.NewTicker(tuneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.tune()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tune takes one sample and resizes the budget
+func (a *autoTuner) tune() {
+	bytes, files := a.tracker.Bytes(), a.tracker.Files()
+	throttled := a.throttled.Load()
+	deltaBytes, deltaFiles := bytes-a.bytes, files-a.files
+	bytesRate := float64(deltaBytes) / tuneInterval.Seconds()
+	filesRate := float64(deltaFiles) / tuneInterval.Seconds()
+	improved := bytesRate > a.bytesRate*1.1 || filesRate > a.filesRate*1.1
+
+	next := a.current
+	reason := ""
+	switch {
+	case throttled > a.lastThrottled:
+		next = max(a.current/2, 1)
+		reason = "throttled"
+	case deltaBytes == 0 && deltaFiles == 0:
+		// Nothing finished, e.g. while waiting for the listing
+	case a.grew && !improved:
+		next = max(a.current-a.step, 1)
+		reason = "no improvement"
+	case improved || a.holds >= probeAfter:
+		a.step = max(a.current/4, 1)
+		if deltaFiles > 0 && deltaBytes/deltaFiles < smallObject {
+			a.step = a.current
+		}
+		next = min(a.current+a.step, a.max)
+		reason = "probing"
+		if improved {
+			reason = "throughput improved"
+		}
+	}
+
+	a.grew = next > a.current
+	if next == a.current {
+		a.holds++
+	} else {
+		a.holds = 0
+		a.logger.Debug("Adjusted parallelism", "from", a.current, "to", next, "reason", reason)
+		a.current = next
+		a.slots.resize(next)
+	}
+
+	a.bytes, a.files = bytes, files
+	a.bytesRate, a.filesRate = bytesRate, filesRate
+	a.lastThrottled = throttled
+}
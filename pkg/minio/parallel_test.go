@@ -0,0 +1,234 @@
+package minio
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zinc-sig/minio-resource/pkg/logging"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+func TestBudgetTryAcquire(t *testing.T) {
+	b := newBudget(2)
+	if !b.tryAcquire() || !b.tryAcquire() {
+		t.Fatal("tryAcquire() failed with free slots")
+	}
+	if b.tryAcquire() {
+		t.Fatal("tryAcquire() took a slot beyond the size")
+	}
+	b.release()
+	if !b.tryAcquire() {
+		t.Fatal("tryAcquire() failed after a release")
+	}
+}
+
+func TestBudgetResize(t *testing.T) {
+	b := newBudget(3)
+	for range 3 {
+		if !b.tryAcquire() {
+			t.Fatal("tryAcquire() failed with free slots")
+		}
+	}
+
+	// Shrinking takes effect as slots are released
+	b.resize(1)
+	b.release()
+	if b.tryAcquire() {
+		t.Fatal("tryAcquire() took a slot with 2 of 1 in use")
+	}
+	b.release()
+	if b.tryAcquire() {
+		t.Fatal("tryAcquire() took a slot with 1 of 1 in use")
+	}
+	b.release()
+	if !b.tryAcquire() {
+		t.Fatal("tryAcquire() failed with 0 of 1 in use")
+	}
+
+	// Growing frees slots at once
+	b.resize(3)
+	if !b.tryAcquire() || !b.tryAcquire() || b.tryAcquire() {
+		t.Fatal("tryAcquire() did not allow exactly 3 slots")
+	}
+}
+
+func TestBudgetAcquireWaits(t *testing.T) {
+	tests := []struct {
+		name string
+		free func(b *budget)
+	}{
+		{name: "release", free: func(b *budget) { b.release() }},
+		{name: "resize", free: func(b *budget) { b.resize(2) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBudget(1)
+			if err := b.acquire(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			acquired := make(chan error)
+			go func() { acquired <- b.acquire(context.Background()) }()
+			select {
+			case err := <-acquired:
+				t.Fatalf("acquire() = %v without a free slot", err)
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			tt.free(b)
+			select {
+			case err := <-acquired:
+				if err != nil {
+					t.Fatalf("acquire() = %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("acquire() still waiting after a slot was freed")
+			}
+		})
+	}
+}
+
+func TestBudgetAcquireCanceled(t *testing.T) {
+	b := newBudget(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.acquire(ctx); err != context.Canceled {
+		t.Fatalf("acquire() = %v, want %v", err, context.Canceled)
+	}
+	if b.tryAcquire() {
+		t.Fatal("a canceled acquire() took a slot")
+	}
+}
+
+// tuneStep is one interval of transfers seen by the auto-tuner
+type tuneStep struct {
+	bytes, files int64
+	throttled    bool
+	want         int
+}
+
+func TestAutoTuner(t *testing.T) {
+	const mib = 1 << 20
+	large := tuneStep{bytes: 100 * mib, files: 10}
+
+	// repeat returns n steps of the same transfers with the same result
+	repeat := func(n int, step tuneStep) []tuneStep {
+		steps := make([]tuneStep, n)
+		for i := range steps {
+			steps[i] = step
+		}
+		return steps
+	}
+	with := func(step tuneStep, want int) tuneStep {
+		step.want = want
+		return step
+	}
+
+	tests := []struct {
+		name  string
+		max   int
+		steps []tuneStep
+	}{
+		{
+			name: "grows by a quarter while throughput improves",
+			max:  64,
+			steps: []tuneStep{
+				with(large, 5),
+				{bytes: 200 * mib, files: 20, want: 6},
+				{bytes: 400 * mib, files: 40, want: 7},
+			},
+		},
+		{
+			name: "steps back when growing did not help",
+			max:  64,
+			steps: []tuneStep{
+				with(large, 5),
+				with(large, 4),
+				with(large, 4),
+			},
+		},
+		{
+			name: "probes again after holding steady",
+			max:  64,
+			steps: append(append([]tuneStep{with(large, 5), with(large, 4)},
+				repeat(probeAfter, with(large, 4))...),
+				with(large, 5)),
+		},
+		{
+			name: "doubles for small objects",
+			max:  64,
+			steps: []tuneStep{
+				{bytes: 10 * 100 << 10, files: 10, want: 8},
+				{bytes: 20 * 100 << 10, files: 20, want: 16},
+			},
+		},
+		{
+			name: "limited to the maximum",
+			max:  6,
+			steps: []tuneStep{
+				{bytes: 10 * 100 << 10, files: 10, want: 6},
+				{bytes: 20 * 100 << 10, files: 20, want: 6},
+			},
+		},
+		{
+			name: "starts below a small maximum",
+			max:  2,
+			steps: []tuneStep{
+				{want: 2},
+			},
+		},
+		{
+			name: "halves when throttled",
+			max:  64,
+			steps: []tuneStep{
+				{bytes: 10 * 100 << 10, files: 10, want: 8},
+				{bytes: 20 * 100 << 10, files: 20, throttled: true, want: 4},
+				{throttled: true, want: 2},
+				{throttled: true, want: 1},
+				{throttled: true, want: 1},
+			},
+		},
+		{
+			name: "holds while nothing finishes",
+			max:  64,
+			steps: append(repeat(probeAfter+1, tuneStep{want: 4}),
+				with(large, 5)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := progress.Start(logging.Discard(), "Downloading", 0, 0, progress.Options{})
+			var throttled atomic.Int64
+			slots := newBudget(tt.max)
+			tuner := newAutoTuner(slots, tt.max, tracker, &throttled, logging.Discard())
+
+			for i, step := range tt.steps {
+				tracker.Add(step.bytes)
+				for range step.files {
+					tracker.FileDone()
+				}
+				if step.throttled {
+					throttled.Add(1)
+				}
+
+				tuner.tune()
+				if tuner.current != step.want {
+					t.Fatalf("step %d: parallelism = %d, want %d", i, tuner.current, step.want)
+				}
+				if got := budgetSize(slots); got != step.want {
+					t.Fatalf("step %d: budget size = %d, want %d", i, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func budgetSize(b *budget) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
@@ -0,0 +1,143 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/minio-go/v7"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+// byteRange is an inclusive range of bytes of an object
+type byteRange struct {
+	start, end int64
+}
+
+// splitRanges divides size bytes into ranges of at most rangeSize bytes
+func splitRanges(size, rangeSize int64) []byteRange {
+	var ranges []byteRange
+	for start := int64(0); start < size; start += rangeSize {
+		ranges = append(ranges, byteRange{start: start, end: min(start+rangeSize, size) - 1})
+	}
+	return ranges
+}
+
+// downloadRanges downloads an object as concurrent ranged GETs written into
+// its preallocated part file, which is moved to destPath once complete.
+// The calling goroutine, which already holds a budget slot, fetches ranges
+// itself and adds helpers whenever the budget has free slots. Every request
+// is pinned to the listed ETag, so an object replaced mid-download fails
+// instead of mixing versions. Ranges written by earlier attempts are
+// skipped.
+func (c *Client) downloadRanges(ctx context.Context, object ObjectInfo, destPath string, rangeSize int64, part *partial, slots *budget, tracker *progress.Tracker) error {
+	file, err := os.OpenFile(part.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", part.path, err)
+	}
+	defer file.Close()
+	if err := file.Truncate(object.Size); err != nil {
+		return fmt.Errorf("failed to allocate file %s: %w", part.path, err)
+	}
+
+	ranges := splitRanges(object.Size, rangeSize)
+	jobs := make(chan byteRange, len(ranges))
+	for _, r := range ranges {
+		if !part.isDone(r) {
+			jobs <- r
+		}
+	}
+	close(jobs)
+
+	resumed := part.doneBytes(ranges)
+	if resumed > 0 {
+		c.logger.Info("Resuming download", "key", object.Path, "offset", humanize.IBytes(uint64(resumed)))
+	}
+	tracker.Add(resumed)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		written  atomic.Int64
+	)
+	fail := func(err error) {
+		errOnce.Do(func() { firstErr = err })
+		cancel()
+	}
+
+	var work func(helper bool)
+	work = func(helper bool) {
+		for r := range jobs {
+			n, err := c.downloadRange(ctx, object, file, r, tracker)
+			written.Add(n)
+			if err == nil {
+				err = part.complete(r)
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			// Only the first goroutine recruits helpers, as slots free up
+			for !helper && len(jobs) > 0 && slots.tryAcquire() {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer slots.release()
+					work(true)
+				}()
+			}
+		}
+	}
+	work(false)
+	wg.Wait()
+
+	if firstErr == nil {
+		if err := file.Close(); err != nil {
+			firstErr = fmt.Errorf("failed to write file %s: %w", part.path, err)
+		}
+	}
+	if firstErr != nil {
+		tracker.Add(-resumed - written.Load())
+		return firstErr
+	}
+	return part.finish(destPath)
+}
+
+// downloadRange fetches one range of an object into file, returning the
+// number of bytes written
+func (c *Client) downloadRange(ctx context.Context, object ObjectInfo, file *os.File, r byteRange, tracker *progress.Tracker) (int64, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(r.start, r.end); err != nil {
+		return 0, err
+	}
+	if object.ETag != "" {
+		if err := opts.SetMatchETag(object.ETag); err != nil {
+			return 0, err
+		}
+	}
+
+	reader, err := c.client.GetObject(ctx, c.bucket, object.Path, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get object %s: %w", object.Path, err)
+	}
+	defer reader.Close()
+
+	length := r.end - r.start + 1
+	written, err := io.Copy(io.NewOffsetWriter(file, r.start), io.LimitReader(tracker.Reader(reader), length))
+	if err != nil {
+		return written, fmt.Errorf("failed to download bytes %d-%d of %s: %w", r.start, r.end, object.Path, err)
+	}
+	if written != length {
+		return written, fmt.Errorf("failed to download bytes %d-%d of %s: %w", r.start, r.end, object.Path, io.ErrUnexpectedEOF)
+	}
+	return written, nil
+}
@@ -0,0 +1,39 @@
+package minio
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitRanges(t *testing.T) {
+	tests := []struct {
+		name            string
+		size, rangeSize int64
+		want            []byteRange
+	}{
+		{name: "empty", size: 0, rangeSize: 4},
+		{name: "smaller than a range", size: 3, rangeSize: 4, want: []byteRange{{0, 2}}},
+		{name: "exactly one range", size: 4, rangeSize: 4, want: []byteRange{{0, 3}}},
+		{name: "exact multiple", size: 8, rangeSize: 4, want: []byteRange{{0, 3}, {4, 7}}},
+		{name: "short last range", size: 10, rangeSize: 4, want: []byteRange{{0, 3}, {4, 7}, {8, 9}}},
+		{name: "single bytes", size: 3, rangeSize: 1, want: []byteRange{{0, 0}, {1, 1}, {2, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitRanges(tt.size, tt.rangeSize)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitRanges(%d, %d) = %v, want %v", tt.size, tt.rangeSize, got, tt.want)
+			}
+
+			// The ranges cover every byte once
+			var covered int64
+			for _, r := range got {
+				covered += r.end - r.start + 1
+			}
+			if covered != tt.size {
+				t.Errorf("ranges cover %d bytes, want %d", covered, tt.size)
+			}
+		})
+	}
+}
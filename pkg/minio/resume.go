@@ -0,0 +1,191 @@
+package minio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// partial is a download in progress. The data is written to a part file
+// next to a JSON sidecar recording the object's ETag and, for ranged
+// downloads, the ranges already written, so that a later attempt can
+// continue where a failed one stopped as long as the object is unchanged.
+type partial struct {
+	path   string
+	shared string
+
+	mu    sync.Mutex
+	state partialState
+}
+
+// partialState is the content of the sidecar
+type partialState struct {
+	ETag      string  `json:"etag"`
+	Size      int64   `json:"size"`
+	RangeSize int64   `json:"range_size,omitempty"`
+	Done      []int64 `json:"done,omitempty"`
+}
+
+// partialPath returns where the cache keeps the partial download of an
+// object. Unlike blobs it is keyed by bucket and key only, so a new
+// version of the object replaces an abandoned download of the old one.
+func (b blobCache) partialPath(bucket, key string) string {
+	sum := sha256.Sum256([]byte(bucket + "\x00" + key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(b.dir, "partial", name[:2], name)
+}
+
+// openPartial continues the partial download at path if its sidecar matches
+// the object and the range size, and starts a new one otherwise. Objects
+// without an ETag cannot be matched and always start over.
+func openPartial(path string, object ObjectInfo, rangeSize int64) (*partial, error) {
+	p := &partial{path: path}
+	want := partialState{ETag: object.ETag, Size: object.Size, RangeSize: rangeSize}
+
+	_, statErr := os.Stat(path)
+	if data, err := os.ReadFile(p.sidecar()); err == nil && statErr == nil && object.ETag != "" {
+		var state partialState
+		if json.Unmarshal(data, &state) == nil &&
+			state.ETag == want.ETag && state.Size == want.Size && state.RangeSize == want.RangeSize {
+			p.state = state
+			return p, nil
+		}
+	}
+
+	// Start over
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	os.Remove(path)
+	p.state = want
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// claimPartial takes over the partial download the cache keeps at shared,
+// moving it to a new file with a random name, so that builds sharing the
+// cache never write to the same file. Renaming the sidecar is what claims
+// the download, as only one build can succeed at it.
+func claimPartial(shared string, object ObjectInfo, rangeSize int64) (*partial, error) {
+	if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", shared, err)
+	}
+	file, err := os.CreateTemp(filepath.Dir(shared), filepath.Base(shared)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create part file for %s: %w", object.Path, err)
+	}
+	file.Close()
+	private := file.Name()
+	if err := os.Chmod(private, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create part file for %s: %w", object.Path, err)
+	}
+
+	if err := os.Rename(shared+".json", private+".json"); err == nil {
+		if err := os.Rename(shared, private); err != nil {
+			// Without its data the sidecar would claim ranges that are not
+			// in the new, empty file
+			os.Remove(private + ".json")
+		}
+	}
+
+	p, err := openPartial(private, object, rangeSize)
+	if err != nil {
+		return nil, err
+	}
+	p.shared = shared
+	return p, nil
+}
+
+func (p *partial) sidecar() string {
+	return p.path + ".json"
+}
+
+// save writes the sidecar under a temporary name and renames it, so that
+// an interrupted write never leaves a sidecar that does not parse
+func (p *partial) save() error {
+	data, err := json.Marshal(p.state)
+	if err != nil {
+		return err
+	}
+	tmp := p.sidecar() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.sidecar(), err)
+	}
+	if err := os.Rename(tmp, p.sidecar()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.sidecar(), err)
+	}
+	return nil
+}
+
+// offset returns how many bytes of a sequential download are already in
+// the part file
+func (p *partial) offset() int64 {
+	if p.state.ETag == "" {
+		return 0
+	}
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return 0
+	}
+	return min(info.Size(), p.state.Size)
+}
+
+// isDone reports whether a range of a ranged download was written before
+func (p *partial) isDone(r byteRange) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state.ETag != "" && slices.Contains(p.state.Done, r.start)
+}
+
+// doneBytes returns the number of bytes in the ranges written before
+func (p *partial) doneBytes(ranges []byteRange) int64 {
+	var n int64
+	for _, r := range ranges {
+		if p.isDone(r) {
+			n += r.end - r.start + 1
+		}
+	}
+	return n
+}
+
+// complete records a written range of a ranged download
+func (p *partial) complete(r byteRange) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state.Done = append(p.state.Done, r.start)
+	return p.save()
+}
+
+// finish moves the completed download to dest and removes the sidecar
+func (p *partial) finish(dest string) error {
+	if err := os.Rename(p.path, dest); err != nil {
+		// The cache may be on another filesystem
+		os.Remove(dest)
+		if err := linkOrCopy(p.path, dest); err != nil {
+			return fmt.Errorf("failed to move download to %s: %w", dest, err)
+		}
+		os.Remove(p.path)
+	}
+	os.Remove(p.sidecar())
+	return nil
+}
+
+// abandon gives up on the download for this run. A partial download
+// claimed from the cache is handed back for a later run, any other is
+// removed.
+func (p *partial) abandon() {
+	if p.shared == "" {
+		os.Remove(p.path)
+		os.Remove(p.sidecar())
+		return
+	}
+	os.Rename(p.path, p.shared)
+	os.Rename(p.sidecar(), p.shared+".json")
+}
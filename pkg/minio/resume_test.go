@@ -0,0 +1,222 @@
+package minio
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writePartial leaves the part file and sidecar of an earlier attempt at path
+func writePartial(t *testing.T, path, data string, state partialState) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := &partial{path: path, state: state}
+	if err := p.save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenPartial(t *testing.T) {
+	object := ObjectInfo{Path: "builds/app.tgz", ETag: "v1", Size: 8}
+	earlier := partialState{ETag: "v1", Size: 8, RangeSize: 4, Done: []int64{4}}
+
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, path string)
+		object     ObjectInfo
+		rangeSize  int64
+		wantResume bool
+	}{
+		{name: "nothing to resume", object: object, rangeSize: 4},
+		{
+			name:       "same version",
+			setup:      func(t *testing.T, path string) { writePartial(t, path, "....data", earlier) },
+			object:     object,
+			rangeSize:  4,
+			wantResume: true,
+		},
+		{
+			name:      "changed etag",
+			setup:     func(t *testing.T, path string) { writePartial(t, path, "....data", earlier) },
+			object:    ObjectInfo{Path: "builds/app.tgz", ETag: "v2", Size: 8},
+			rangeSize: 4,
+		},
+		{
+			name:      "changed size",
+			setup:     func(t *testing.T, path string) { writePartial(t, path, "....data", earlier) },
+			object:    ObjectInfo{Path: "builds/app.tgz", ETag: "v1", Size: 9},
+			rangeSize: 4,
+		},
+		{
+			name:      "changed range size",
+			setup:     func(t *testing.T, path string) { writePartial(t, path, "....data", earlier) },
+			object:    object,
+			rangeSize: 2,
+		},
+		{
+			name: "object without etag",
+			setup: func(t *testing.T, path string) {
+				writePartial(t, path, "....data", partialState{Size: 8, RangeSize: 4})
+			},
+			object:    ObjectInfo{Path: "builds/app.tgz", Size: 8},
+			rangeSize: 4,
+		},
+		{
+			name: "sidecar without data",
+			setup: func(t *testing.T, path string) {
+				writePartial(t, path, "....data", earlier)
+				os.Remove(path)
+			},
+			object:    object,
+			rangeSize: 4,
+		},
+		{
+			name: "sidecar that does not parse",
+			setup: func(t *testing.T, path string) {
+				writePartial(t, path, "....data", earlier)
+				if err := os.WriteFile(path+".json", []byte("{"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			object:    object,
+			rangeSize: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dir", ".app.tgz.part")
+			if tt.setup != nil {
+				tt.setup(t, path)
+			}
+
+			p, err := openPartial(path, tt.object, tt.rangeSize)
+			if err != nil {
+				t.Fatalf("openPartial() = %v", err)
+			}
+
+			if tt.wantResume {
+				if !p.isDone(byteRange{4, 7}) || p.isDone(byteRange{0, 3}) {
+					t.Errorf("done ranges = %v, want [4]", p.state.Done)
+				}
+				if p.offset() != 8 {
+					t.Errorf("offset() = %d, want 8", p.offset())
+				}
+				return
+			}
+
+			// A new download starts from an empty part file and a fresh sidecar
+			want := partialState{ETag: tt.object.ETag, Size: tt.object.Size, RangeSize: tt.rangeSize}
+			if p.state.ETag != want.ETag || p.state.Size != want.Size || p.state.RangeSize != want.RangeSize || len(p.state.Done) > 0 {
+				t.Errorf("state = %+v, want %+v", p.state, want)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("stale part file kept: %v", err)
+			}
+			if p.offset() != 0 || p.doneBytes(splitRanges(8, 4)) != 0 {
+				t.Errorf("offset() = %d, doneBytes() = %d, want 0", p.offset(), p.doneBytes(splitRanges(8, 4)))
+			}
+
+			reopened, err := openPartial(path, tt.object, tt.rangeSize)
+			if err != nil || reopened.state.ETag != want.ETag {
+				t.Errorf("sidecar not saved: %+v, %v", reopened.state, err)
+			}
+		})
+	}
+}
+
+func TestClaimPartial(t *testing.T) {
+	object := ObjectInfo{Path: "builds/app.tgz", ETag: "v1", Size: 8}
+	state := partialState{ETag: "v1", Size: 8, RangeSize: 4, Done: []int64{0}}
+
+	t.Run("resumes the shared download", func(t *testing.T) {
+		shared := filepath.Join(t.TempDir(), "partial", "ab", "abcd")
+		writePartial(t, shared, "data....", state)
+
+		p, err := claimPartial(shared, object, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.path == shared || !p.isDone(byteRange{0, 3}) {
+			t.Errorf("claimed %s with %+v, want a private copy of the shared download", p.path, p.state)
+		}
+		if _, err := os.Stat(shared); !os.IsNotExist(err) {
+			t.Errorf("shared part file still there: %v", err)
+		}
+		if info, err := os.Stat(p.path); err != nil || info.Mode().Perm() != 0644 {
+			t.Errorf("part file = %v, %v, want mode 0644", info, err)
+		}
+
+		// A second build meanwhile starts its own download
+		other, err := claimPartial(shared, object, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.path == p.path || other.isDone(byteRange{0, 3}) {
+			t.Errorf("second claim = %s with %+v, want a new download", other.path, other.state)
+		}
+		other.abandon()
+
+		// Abandoning hands the download back for a later build
+		p.abandon()
+		again, err := claimPartial(shared, object, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !again.isDone(byteRange{0, 3}) {
+			t.Errorf("abandoned download not resumed: %+v", again.state)
+		}
+		data, err := os.ReadFile(again.path)
+		if err != nil || string(data) != "data...." {
+			t.Errorf("resumed part file = %q, %v", data, err)
+		}
+	})
+
+	t.Run("sidecar without data", func(t *testing.T) {
+		shared := filepath.Join(t.TempDir(), "abcd")
+		writePartial(t, shared, "data....", state)
+		os.Remove(shared)
+
+		p, err := claimPartial(shared, object, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.isDone(byteRange{0, 3}) {
+			t.Errorf("claimed ranges without their data: %+v", p.state)
+		}
+	})
+
+	t.Run("finish moves the download into place", func(t *testing.T) {
+		dir := t.TempDir()
+		shared := filepath.Join(dir, "abcd")
+		p, err := claimPartial(shared, object, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p.path, []byte("complete"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		dest := filepath.Join(dir, "app.tgz")
+		if err := p.finish(dest); err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(dest); err != nil || string(data) != "complete" {
+			t.Errorf("dest = %q, %v", data, err)
+		}
+		entries, _ := os.ReadDir(dir)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if !slices.Equal(names, []string{"app.tgz"}) {
+			t.Errorf("left behind %v, want only app.tgz", names)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Error classes reported in transfer statistics
+const (
+	ErrorNotFound     = "not_found"
+	ErrorAccessDenied = "access_denied"
+	ErrorThrottled    = "throttled"
+	ErrorServer       = "server"
+	ErrorTimeout      = "timeout"
+	ErrorNetwork      = "network"
+	ErrorLocal        = "local_io"
+	ErrorCanceled     = "canceled"
+	ErrorOther        = "other"
+)
+
+// ErrorClass groups an error into one of the classes above, so failures can
+// be counted by cause
+func ErrorClass(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return ErrorCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+
+	var response minio.ErrorResponse
+	if errors.As(err, &response) {
+		switch response.Code {
+		case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+			return ErrorNotFound
+		case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken":
+			return ErrorAccessDenied
+		case "SlowDown", "SlowDownRead", "SlowDownWrite", "ServerBusy", "RequestLimitExceeded":
+			return ErrorThrottled
+		case "RequestTimeout":
+			return ErrorTimeout
+		}
+		switch {
+		case response.StatusCode == http.StatusNotFound:
+			return ErrorNotFound
+		case response.StatusCode == http.StatusForbidden:
+			return ErrorAccessDenied
+		case response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable:
+			return ErrorThrottled
+		case response.StatusCode >= 500:
+			return ErrorServer
+		}
+		return ErrorOther
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorNetwork
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return ErrorLocal
+	}
+	return ErrorOther
+}
+
+// retryable reports whether an error of the given class may go away when
+// the operation is repeated. Other errors, such as a bad request or a failed
+// precondition, fail the same way every time.
+func retryable(class string) bool {
+	switch class {
+	case ErrorThrottled, ErrorServer, ErrorTimeout, ErrorNetwork:
+		return true
+	}
+	return false
+}
+
+// Retry calls fn until it succeeds, fails with an error that cannot be
+// fixed by retrying, or has been retried retries times, backing off
+// exponentially between attempts. It returns the errors of the attempts
+// that were retried, followed by the final error.
+func (c *Client) Retry(ctx context.Context, retries int, name string, fn func() error) ([]error, error) {
+	var retried []error
+	backoff := 500 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !retryable(ErrorClass(err)) || ctx.Err() != nil {
+			return retried, err
+		}
+		retried = append(retried, err)
+
+		c.logger.Warn("Retrying", "name", name, "attempt", attempt+2, "error", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return retried, ctx.Err()
+		}
+		backoff = min(backoff*2, 10*time.Second)
+	}
+}
@@ -0,0 +1,99 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/zinc-sig/minio-resource/pkg/logging"
+)
+
+func TestErrorClass(t *testing.T) {
+	response := func(status int, code string) error {
+		return minio.ErrorResponse{StatusCode: status, Code: code}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		want      string
+		wantRetry bool
+	}{
+		{name: "canceled", err: context.Canceled, want: ErrorCanceled},
+		{name: "wrapped canceled", err: fmt.Errorf("download: %w", context.Canceled), want: ErrorCanceled},
+		{name: "deadline", err: context.DeadlineExceeded, want: ErrorTimeout, wantRetry: true},
+		{name: "no such key", err: response(http.StatusNotFound, "NoSuchKey"), want: ErrorNotFound},
+		{name: "no such upload", err: response(http.StatusNotFound, "NoSuchUpload"), want: ErrorNotFound},
+		{name: "404 without code", err: response(http.StatusNotFound, ""), want: ErrorNotFound},
+		{name: "access denied", err: response(http.StatusForbidden, "AccessDenied"), want: ErrorAccessDenied},
+		{name: "bad signature", err: response(http.StatusForbidden, "SignatureDoesNotMatch"), want: ErrorAccessDenied},
+		{name: "403 without code", err: response(http.StatusForbidden, ""), want: ErrorAccessDenied},
+		{name: "slow down", err: response(http.StatusServiceUnavailable, "SlowDown"), want: ErrorThrottled, wantRetry: true},
+		{name: "too many requests", err: response(http.StatusTooManyRequests, ""), want: ErrorThrottled, wantRetry: true},
+		{name: "unavailable", err: response(http.StatusServiceUnavailable, ""), want: ErrorThrottled, wantRetry: true},
+		{name: "request timeout", err: response(http.StatusBadRequest, "RequestTimeout"), want: ErrorTimeout, wantRetry: true},
+		{name: "internal error", err: response(http.StatusInternalServerError, "InternalError"), want: ErrorServer, wantRetry: true},
+		{name: "bad gateway", err: response(http.StatusBadGateway, ""), want: ErrorServer, wantRetry: true},
+		{name: "bad request", err: response(http.StatusBadRequest, "InvalidArgument"), want: ErrorOther},
+		{name: "precondition failed", err: response(http.StatusPreconditionFailed, "PreconditionFailed"), want: ErrorOther},
+		{name: "wrapped response", err: fmt.Errorf("copy: %w", response(http.StatusInternalServerError, "")), want: ErrorServer, wantRetry: true},
+		{name: "network timeout", err: &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, want: ErrorTimeout, wantRetry: true},
+		{name: "dns failure", err: &net.DNSError{Err: "no such host", Name: "minio"}, want: ErrorNetwork, wantRetry: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: ErrorNetwork, wantRetry: true},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: ErrorNetwork, wantRetry: true},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, want: ErrorNetwork, wantRetry: true},
+		{name: "local file", err: &fs.PathError{Op: "open", Path: "/tmp/x", Err: fs.ErrPermission}, want: ErrorLocal},
+		{name: "unknown", err: errors.New("something else"), want: ErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ErrorClass(tt.err)
+			if got != tt.want {
+				t.Errorf("ErrorClass() = %q, want %q", got, tt.want)
+			}
+			if retryable(got) != tt.wantRetry {
+				t.Errorf("retryable(%q) = %v, want %v", got, retryable(got), tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	client := &Client{logger: logging.Discard()}
+
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{name: "success", wantAttempts: 1},
+		{name: "bad request not retried", err: minio.ErrorResponse{StatusCode: http.StatusBadRequest, Code: "InvalidArgument"}, wantAttempts: 1},
+		{name: "not found not retried", err: minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}, wantAttempts: 1},
+		{name: "server error retried", err: minio.ErrorResponse{StatusCode: http.StatusInternalServerError}, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			retried, err := client.Retry(context.Background(), 1, "object", func() error {
+				attempts++
+				return tt.err
+			})
+			if (err == nil) != (tt.err == nil) {
+				t.Errorf("Retry() error = %v, want %v", err, tt.err)
+			}
+			if attempts != tt.wantAttempts || len(retried) != tt.wantAttempts-1 {
+				t.Errorf("attempts = %d, retried = %d, want %d attempts", attempts, len(retried), tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -15,6 +17,16 @@ type Source struct {
 	UseSSL              *bool  `json:"use_ssl,omitempty"`
 	Region              string `json:"region,omitempty"`
 	SkipSSLVerification bool   `json:"skip_ssl_verification,omitempty"`
+
+	LogLevel         LogLevel      `json:"log_level,omitempty"`
+	LogFormat        LogFormat     `json:"log_format,omitempty"`
+	ProgressInterval time.Duration `json:"progress_interval,omitempty"`
+	CacheDir         string        `json:"cache_dir,omitempty"`
+	CacheMaxSize     Size          `json:"cache_max_size,omitempty"`
+
+	CheckParams
+	UploadParams
+	LimitParams
 }
 
 // Version represents a specific version of the resource
@@ -24,6 +36,20 @@ type Version struct {
 	LastModified time.Time `json:"last_modified"`
 }
 
+// Compare orders versions by last modified time, then path, then etag.
+// It returns -1, 0 or +1 and is the cursor order used by the check script.
+// Times are compared at second precision, which is what survives a round
+// trip through Concourse.
+func (v Version) Compare(other Version) int {
+	if c := v.LastModified.Truncate(time.Second).Compare(other.LastModified.Truncate(time.Second)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(v.Path, other.Path); c != 0 {
+		return c
+	}
+	return strings.Compare(v.ETag, other.ETag)
+}
+
 // CheckRequest is the input for the check script
 type CheckRequest struct {
 	Source  Source  `json:"source"`
@@ -35,9 +61,9 @@ type CheckResponse []Version
 
 // InRequest is the input for the in script
 type InRequest struct {
-	Source  Source         `json:"source"`
-	Version Version        `json:"version"`
-	Params  map[string]any `json:"params,omitempty"`
+	Source  Source   `json:"source"`
+	Version Version  `json:"version"`
+	Params  InParams `json:"params,omitempty"`
 }
 
 // InResponse is the output from the in script
@@ -48,8 +74,8 @@ type InResponse struct {
 
 // OutRequest is the input for the out script
 type OutRequest struct {
-	Source Source         `json:"source"`
-	Params map[string]any `json:"params,omitempty"`
+	Source Source    `json:"source"`
+	Params OutParams `json:"params,omitempty"`
 }
 
 // OutResponse is the output from the out script
@@ -58,6 +84,15 @@ type OutResponse struct {
 	Metadata []Metadata `json:"metadata,omitempty"`
 }
 
+// PresignedURL is a presigned download link for an object, as written to
+// .resource_urls.json by the in script
+type PresignedURL struct {
+	Path      string    `json:"path"`
+	Key       string    `json:"key"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Metadata represents key-value pairs returned by in/out scripts
 type Metadata struct {
 	Name  string `json:"name"`
@@ -72,6 +107,41 @@ func (s *Source) UseSSLValue() bool {
 	return *s.UseSSL
 }
 
+// defaultCacheMaxSize is the size cache_dir is pruned to by default
+const defaultCacheMaxSize Size = 10 << 30
+
+// CacheMaxSizeValue returns the size cache_dir is pruned to after a get,
+// defaulting to 10GiB
+func (s *Source) CacheMaxSizeValue() Size {
+	if s.CacheMaxSize == 0 {
+		return defaultCacheMaxSize
+	}
+	return s.CacheMaxSize
+}
+
+// CheckSinceTime returns the time before which objects are ignored by check.
+// CheckSince may be an RFC3339 timestamp or a duration relative to now, such
+// as "72h". The zero time is returned when CheckSince is not set.
+func (s *Source) CheckSinceTime(now time.Time) (time.Time, error) {
+	if s.CheckSince == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s.CheckSince); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s.CheckSince)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC3339 timestamp or a duration: %q", s.CheckSince)
+	}
+	return now.Add(-d), nil
+}
+
+// UnmarshalJSON decodes the source strictly, rejecting unknown keys
+func (s *Source) UnmarshalJSON(data []byte) error {
+	type plain Source
+	return decodeStrict("source", data, (*plain)(s))
+}
+
 // UnmarshalJSON implements custom unmarshaling for Version to handle time parsing
 func (v *Version) UnmarshalJSON(data []byte) error {
 	type Alias Version
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVersionCompare(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		a, b Version
+		want int
+	}{
+		{
+			name: "older time first",
+			a:    Version{Path: "b", LastModified: base},
+			b:    Version{Path: "a", LastModified: base.Add(time.Second)},
+			want: -1,
+		},
+		{
+			name: "newer time last",
+			a:    Version{Path: "a", LastModified: base.Add(time.Second)},
+			b:    Version{Path: "b", LastModified: base},
+			want: 1,
+		},
+		{
+			name: "equal times by path",
+			a:    Version{Path: "a", LastModified: base},
+			b:    Version{Path: "b", LastModified: base},
+			want: -1,
+		},
+		{
+			name: "equal times and paths by etag",
+			a:    Version{Path: "a", ETag: "2", LastModified: base},
+			b:    Version{Path: "a", ETag: "1", LastModified: base},
+			want: 1,
+		},
+		{
+			name: "identical",
+			a:    Version{Path: "a", ETag: "1", LastModified: base},
+			b:    Version{Path: "a", ETag: "1", LastModified: base},
+			want: 0,
+		},
+		{
+			name: "sub-second differences are ignored",
+			a:    Version{Path: "b", LastModified: base.Add(900 * time.Millisecond)},
+			b:    Version{Path: "a", LastModified: base.Add(100 * time.Millisecond)},
+			want: 1,
+		},
+		{
+			name: "time zones do not matter",
+			a:    Version{Path: "a", LastModified: base.In(time.FixedZone("CEST", 2*60*60))},
+			b:    Version{Path: "a", LastModified: base},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Compare(tt.b); got != tt.want {
+				t.Errorf("Compare() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ObjectMetadata describes a downloaded object. With write_metadata, in
+// writes it next to every file or collected into one file, so tasks can
+// read object metadata without their own S3 client.
+type ObjectMetadata struct {
+	Key          string            `json:"key"`
+	Path         string            `json:"path"`
+	ETag         string            `json:"etag"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"last_modified"`
+	ContentType  string            `json:"content_type,omitempty"`
+	UserMetadata map[string]string `json:"user_metadata,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	VersionID    string            `json:"version_id,omitempty"`
+	StorageClass string            `json:"storage_class,omitempty"`
+	Checksums    map[string]string `json:"checksums,omitempty"`
+}
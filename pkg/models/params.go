@@ -0,0 +1,756 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// CheckParams are the source options that only affect the check script.
+// Concourse does not pass params to check, so they live inline in Source.
+type CheckParams struct {
+	InitialPath    string   `json:"initial_path,omitempty"`
+	InitialVersion *Version `json:"initial_version,omitempty"`
+	MaxVersions    int      `json:"max_versions,omitempty"`
+	CheckSince     string   `json:"check_since,omitempty"`
+	OrderedKeys    bool     `json:"ordered_keys,omitempty"`
+}
+
+// InParams are the params accepted by the in script
+type InParams struct {
+	Parallel           Parallel      `json:"parallel,omitempty"`
+	Presign            Presign       `json:"presign,omitempty"`
+	DryRun             bool          `json:"dry_run,omitempty"`
+	LogLevel           LogLevel      `json:"log_level,omitempty"`
+	ProgressInterval   time.Duration `json:"progress_interval,omitempty"`
+	Retries            *int          `json:"retries,omitempty"`
+	CacheDir           string        `json:"cache_dir,omitempty"`
+	RangeThreshold     Size          `json:"range_threshold,omitempty"`
+	RangeSize          Size          `json:"range_size,omitempty"`
+	PreserveAttributes bool          `json:"preserve_attributes,omitempty"`
+	WriteMetadata      WriteMetadata `json:"write_metadata,omitempty"`
+	Prefix             string        `json:"prefix,omitempty"`
+	Paths              []string      `json:"paths,omitempty"`
+	Flatten            bool          `json:"flatten,omitempty"`
+
+	LimitParams
+}
+
+// OutParams are the params accepted by the out script
+type OutParams struct {
+	Action             string        `json:"action,omitempty"`
+	UploadEnabled      bool          `json:"upload_enabled,omitempty"`
+	File               string        `json:"file,omitempty"`
+	Paths              []string      `json:"paths,omitempty"`
+	ToBucket           string        `json:"to_bucket,omitempty"`
+	ToPrefix           string        `json:"to_prefix,omitempty"`
+	PromoteFrom        PromoteFrom   `json:"promote_from,omitempty"`
+	Presign            Presign       `json:"presign,omitempty"`
+	DryRun             bool          `json:"dry_run,omitempty"`
+	LogLevel           LogLevel      `json:"log_level,omitempty"`
+	ProgressInterval   time.Duration `json:"progress_interval,omitempty"`
+	Retries            *int          `json:"retries,omitempty"`
+	PreserveAttributes bool          `json:"preserve_attributes,omitempty"`
+
+	UploadParams
+	LimitParams
+}
+
+// UploadParams tune how out uploads objects. They are set in source and
+// can be overridden by the params of out.
+type UploadParams struct {
+	PartSize         Size `json:"part_size,omitempty"`
+	UploadThreads    int  `json:"upload_threads,omitempty"`
+	DisableMultipart bool `json:"disable_multipart,omitempty"`
+}
+
+// Multipart upload limits of S3 and MinIO
+const (
+	MinPartSize  Size = 5 << 20
+	MaxPartSize  Size = 5 << 30
+	MaxPartCount      = 10000
+)
+
+// Merge returns the upload params with the fields set in override applied
+func (u UploadParams) Merge(override UploadParams) UploadParams {
+	if override.PartSize != 0 {
+		u.PartSize = override.PartSize
+	}
+	if override.UploadThreads != 0 {
+		u.UploadThreads = override.UploadThreads
+	}
+	if override.DisableMultipart {
+		u.DisableMultipart = true
+	}
+	return u
+}
+
+// LimitParams cap the load a build puts on the server, across all
+// concurrent transfers. They are set in source and can be overridden by
+// the params of in and out.
+type LimitParams struct {
+	MaxBandwidth         Bandwidth `json:"max_bandwidth,omitempty"`
+	MaxRequestsPerSecond int       `json:"max_requests_per_second,omitempty"`
+}
+
+// minBandwidth is the lowest max_bandwidth accepted
+const minBandwidth Bandwidth = 1 << 10
+
+// Merge returns the limits with the fields set in override applied
+func (l LimitParams) Merge(override LimitParams) LimitParams {
+	if override.MaxBandwidth != 0 {
+		l.MaxBandwidth = override.MaxBandwidth
+	}
+	if override.MaxRequestsPerSecond != 0 {
+		l.MaxRequestsPerSecond = override.MaxRequestsPerSecond
+	}
+	return l
+}
+
+// defaultRetries is how often a failed transfer is retried by default
+const defaultRetries = 2
+
+// RetriesValue returns how often a failed download is retried
+func (p InParams) RetriesValue() int {
+	if p.Retries == nil {
+		return defaultRetries
+	}
+	return *p.Retries
+}
+
+// Ranged download defaults
+const (
+	defaultRangeThreshold Size = 256 << 20
+	defaultRangeSize      Size = 64 << 20
+	minRangeSize          Size = 1 << 20
+)
+
+// RangeThresholdValue returns the size from which objects are downloaded
+// in ranges
+func (p InParams) RangeThresholdValue() Size {
+	if p.RangeThreshold == 0 {
+		return defaultRangeThreshold
+	}
+	return p.RangeThreshold
+}
+
+// RangeSizeValue returns the size of the ranges of a ranged download
+func (p InParams) RangeSizeValue() Size {
+	if p.RangeSize == 0 {
+		return defaultRangeSize
+	}
+	return p.RangeSize
+}
+
+// RetriesValue returns how often a failed upload is retried
+func (p OutParams) RetriesValue() int {
+	if p.Retries == nil {
+		return defaultRetries
+	}
+	return *p.Retries
+}
+
+// Actions performed by the out script
+const (
+	ActionUpload = "upload"
+	ActionDelete = "delete"
+	ActionMove   = "move"
+	ActionCopy   = "copy"
+)
+
+// ActionValue returns the action, defaulting to upload
+func (p OutParams) ActionValue() string {
+	if p.Action == "" {
+		return ActionUpload
+	}
+	return p.Action
+}
+
+// PromoteFrom identifies an object to copy into this resource, usually one
+// fetched by a get of another resource. It is either the directory of that
+// get step, whose version file names the object, or an object with the
+// object's path and etag.
+type PromoteFrom struct {
+	Dir    string `json:"dir,omitempty"`
+	Bucket string `json:"bucket,omitempty"`
+	Path   string `json:"path,omitempty"`
+	ETag   string `json:"etag,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// IsSet reports whether promote_from was given
+func (p PromoteFrom) IsSet() bool {
+	return p.Dir != "" || p.Path != ""
+}
+
+// UnmarshalJSON accepts a get step directory or an object
+func (p *PromoteFrom) UnmarshalJSON(data []byte) error {
+	var dir string
+	if err := json.Unmarshal(data, &dir); err == nil {
+		*p = PromoteFrom{Dir: dir}
+		return nil
+	}
+
+	type plain PromoteFrom
+	return decodeStrict("", data, (*plain)(p))
+}
+
+// LogLevel is the minimum level of log messages: debug, info, warn or error
+type LogLevel string
+
+// UnmarshalJSON accepts the level names in any case
+func (l *LogLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("must be a string, got %s", describe(data))
+	}
+	level := strings.ToLower(name)
+	switch level {
+	case "debug", "info", "warn", "error":
+	case "warning":
+		level = "warn"
+	default:
+		return fmt.Errorf("%q must be one of debug, info, warn or error", name)
+	}
+	*l = LogLevel(level)
+	return nil
+}
+
+// LogFormat is the format of log messages: text or json
+type LogFormat string
+
+// UnmarshalJSON accepts text or json
+func (f *LogFormat) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("must be a string, got %s", describe(data))
+	}
+	format := strings.ToLower(name)
+	if format != "text" && format != "json" {
+		return fmt.Errorf("%q must be text or json", name)
+	}
+	*f = LogFormat(format)
+	return nil
+}
+
+// Presign configures presigned download URLs. It is either a boolean or an
+// object with an expires duration.
+type Presign struct {
+	Enabled bool          `json:"-"`
+	Expires time.Duration `json:"expires,omitempty"`
+}
+
+// UnmarshalJSON decodes params strictly, rejecting unknown keys
+func (p *InParams) UnmarshalJSON(data []byte) error {
+	type plain InParams
+	return decodeStrict("params", data, (*plain)(p))
+}
+
+// UnmarshalJSON decodes params strictly, rejecting unknown keys
+func (p *OutParams) UnmarshalJSON(data []byte) error {
+	type plain OutParams
+	return decodeStrict("params", data, (*plain)(p))
+}
+
+// UnmarshalJSON accepts a boolean or an object with an expires duration
+func (p *Presign) UnmarshalJSON(data []byte) error {
+	if enabled, err := parseBool(data); err == nil {
+		*p = Presign{Enabled: enabled}
+		return nil
+	}
+
+	type plain Presign
+	if err := decodeStrict("", data, (*plain)(p)); err != nil {
+		return err
+	}
+	if p.Expires > 7*24*time.Hour {
+		return &FieldError{Path: "expires", Err: errors.New("must not exceed 168h")}
+	}
+	p.Enabled = true
+	return nil
+}
+
+// ExpiresValue returns how long presigned URLs stay valid, defaulting to 24
+// hours if not set
+func (p Presign) ExpiresValue() time.Duration {
+	if p.Expires == 0 {
+		return 24 * time.Hour
+	}
+	return p.Expires
+}
+
+// WriteMetadata selects how in writes object metadata: a sidecar file
+// next to every downloaded file, or one combined file. True selects
+// sidecar files.
+type WriteMetadata string
+
+// Ways of writing object metadata
+const (
+	WriteMetadataSidecar  WriteMetadata = "sidecar"
+	WriteMetadataCombined WriteMetadata = "combined"
+)
+
+// UnmarshalJSON accepts a boolean, sidecar or combined
+func (w *WriteMetadata) UnmarshalJSON(data []byte) error {
+	if enabled, err := parseBool(data); err == nil {
+		*w = ""
+		if enabled {
+			*w = WriteMetadataSidecar
+		}
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("must be a boolean or a string, got %s", describe(data))
+	}
+	mode := WriteMetadata(strings.ToLower(name))
+	if mode != WriteMetadataSidecar && mode != WriteMetadataCombined {
+		return fmt.Errorf("%q must be true, false, sidecar or combined", name)
+	}
+	*w = mode
+	return nil
+}
+
+// Parallel is a number of concurrent transfers, or ParallelAuto to adapt
+// it to the observed throughput
+type Parallel int
+
+// ParallelAuto is written as parallel: auto
+const ParallelAuto Parallel = -1
+
+// MarshalJSON writes auto or the number
+func (p Parallel) MarshalJSON() ([]byte, error) {
+	if p == ParallelAuto {
+		return json.Marshal("auto")
+	}
+	return json.Marshal(int(p))
+}
+
+// UnmarshalJSON accepts auto or a number, where zero selects the default
+func (p *Parallel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil && strings.EqualFold(strings.TrimSpace(s), "auto") {
+		*p = ParallelAuto
+		return nil
+	}
+	n, err := parseInt(data)
+	if err != nil || n < 0 {
+		return fmt.Errorf("expected auto or a number, got %s", describe(data))
+	}
+	*p = Parallel(n)
+	return nil
+}
+
+// Bandwidth is a number of bytes per second, written as a size with an
+// optional "/s" suffix such as "100MiB/s", or as a plain number
+type Bandwidth int64
+
+// MarshalJSON writes the bandwidth in its IEC string form
+func (b Bandwidth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(humanize.IBytes(uint64(b)) + "/s")
+}
+
+// UnmarshalJSON accepts a size per second
+func (b *Bandwidth) UnmarshalJSON(data []byte) error {
+	size := data
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		size, _ = json.Marshal(strings.TrimSuffix(strings.TrimSpace(s), "/s"))
+	}
+	n, err := parseSize(size)
+	if err != nil {
+		return fmt.Errorf("expected a bandwidth such as \"100MiB/s\", got %s", describe(data))
+	}
+	*b = Bandwidth(n)
+	return nil
+}
+
+// Size is a number of bytes, written in params as a string such as "64MiB"
+// or "1GB", or as a plain number
+type Size int64
+
+// MarshalJSON writes the size in its IEC string form
+func (s Size) MarshalJSON() ([]byte, error) {
+	return json.Marshal(humanize.IBytes(uint64(s)))
+}
+
+// FieldError is a decoding problem at a field path such as params.parallel
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeRequest decodes a check, in or out request from r. Unlike
+// json.Decoder it does not stop at the first problem, so errors in source and
+// params are reported together. Unknown top-level keys are ignored, as
+// Concourse may add new ones.
+func DecodeRequest(r io.Reader, v any) error {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&fields); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	known := structFields(rv)
+	keys := make([]string, 0, len(known))
+	for key := range known {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		data, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(data, known[key].Addr().Interface()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DecodeStrict decodes JSON into v, rejecting unknown object keys. Booleans,
+// integers, durations and sizes are normalised: booleans and integers may be
+// given as strings, durations as strings such as "30s" or numbers of seconds,
+// and sizes as strings such as "64MiB" or numbers of bytes. All problems are
+// reported at once as FieldErrors joined with errors.Join, each carrying the
+// path of the offending field below root.
+func DecodeStrict(root string, data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer")
+	}
+	return decodeStrict(root, data, v)
+}
+
+func decodeStrict(root string, data []byte, v any) error {
+	return decodeValue(root, data, reflect.ValueOf(v).Elem(), true)
+}
+
+var (
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	durationType    = reflect.TypeFor[time.Duration]()
+	sizeType        = reflect.TypeFor[Size]()
+)
+
+// decodeValue decodes data into v. Types implementing json.Unmarshaler are
+// left to their own decoding, except for the top-level value which is the
+// one being decoded on behalf of its UnmarshalJSON.
+func decodeValue(path string, data []byte, v reflect.Value, top bool) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	if !top && v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if err := v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+			return prefixErrors(path, err)
+		}
+		return nil
+	}
+
+	// Normalise scalars that pipelines commonly write as strings
+	switch {
+	case v.Type() == durationType:
+		d, err := parseDuration(data)
+		if err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Type() == sizeType:
+		n, err := parseSize(data)
+		if err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		v.SetInt(n)
+		return nil
+	case v.Kind() == reflect.Bool:
+		b, err := parseBool(data)
+		if err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		v.SetBool(b)
+		return nil
+	case v.CanInt():
+		n, err := parseInt(data)
+		if err != nil {
+			return &FieldError{Path: path, Err: err}
+		}
+		v.SetInt(n)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(path, data, v.Elem(), false)
+
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return &FieldError{Path: path, Err: fmt.Errorf("expected an object, got %s", describe(data))}
+		}
+
+		known := structFields(v)
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var errs []error
+		for _, key := range keys {
+			field, ok := known[key]
+			if !ok {
+				errs = append(errs, &FieldError{Path: joinPath(path, key), Err: errors.New("unknown field")})
+				continue
+			}
+			if err := decodeValue(joinPath(path, key), fields[key], field, false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return &FieldError{Path: path, Err: fmt.Errorf("expected a list, got %s", describe(data))}
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		var errs []error
+		for i, item := range items {
+			if err := decodeValue(fmt.Sprintf("%s[%d]", path, i), item, slice.Index(i), false); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		v.Set(slice)
+		return errors.Join(errs...)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() == reflect.Interface {
+			break
+		}
+
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return &FieldError{Path: path, Err: fmt.Errorf("expected an object, got %s", describe(data))}
+		}
+
+		keys := make([]string, 0, len(items))
+		for key := range items {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		m := reflect.MakeMapWithSize(v.Type(), len(items))
+		var errs []error
+		for _, key := range keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(joinPath(path, key), items[key], elem, false); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+		return errors.Join(errs...)
+	}
+
+	if err := json.Unmarshal(data, v.Addr().Interface()); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &FieldError{Path: path, Err: fmt.Errorf("expected %s, got %s", describeKind(v.Kind()), describe(data))}
+		}
+		return &FieldError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// parseBool accepts a boolean or one of the strings "true", "false", "yes",
+// "no", "1" and "0"
+func parseBool(data []byte) (bool, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err == nil {
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "1":
+				return true, nil
+			case "false", "no", "0":
+				return false, nil
+			}
+		}
+	}
+	return false, fmt.Errorf("expected a boolean, got %s", describe(data))
+}
+
+// parseInt accepts an integer or a numeric string
+func parseInt(data []byte) (int64, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err == nil {
+		switch v := v.(type) {
+		case float64:
+			if v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %s", describe(data))
+}
+
+// parseDuration accepts a duration string such as "30s" or a number of
+// seconds
+func parseDuration(data []byte) (time.Duration, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err == nil {
+		switch v := v.(type) {
+		case float64:
+			if v >= 0 {
+				return time.Duration(v * float64(time.Second)), nil
+			}
+		case string:
+			if d, err := time.ParseDuration(strings.TrimSpace(v)); err == nil && d >= 0 {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("expected a duration such as \"30s\", got %s", describe(data))
+}
+
+// parseSize accepts a size string such as "64MiB" or a number of bytes
+func parseSize(data []byte) (int64, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err == nil {
+		switch v := v.(type) {
+		case float64:
+			if v >= 0 && v == float64(int64(v)) {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := humanize.ParseBytes(strings.TrimSpace(v)); err == nil && n <= math.MaxInt64 {
+				return int64(n), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("expected a size such as \"64MiB\", got %s", describe(data))
+}
+
+// structFields maps the JSON names of a struct's fields to their values,
+// flattening embedded structs the way encoding/json does
+func structFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for key, value := range structFields(v.Field(i)) {
+				fields[key] = value
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+// prefixErrors places the errors of a nested decode below path
+func prefixErrors(path string, err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		prefixed := make([]error, len(errs))
+		for i, err := range errs {
+			prefixed[i] = prefixErrors(path, err)
+		}
+		return errors.Join(prefixed...)
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return &FieldError{Path: joinPath(path, fieldErr.Path), Err: fieldErr.Err}
+	}
+	return &FieldError{Path: path, Err: err}
+}
+
+func joinPath(path, key string) string {
+	switch {
+	case path == "":
+		return key
+	case key == "" || strings.HasPrefix(key, "["):
+		return path + key
+	default:
+		return path + "." + key
+	}
+}
+
+// describe summarises a JSON value for error messages
+func describe(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if len(data) > 40 {
+		return string(data[:37]) + "..."
+	}
+	return string(data)
+}
+
+func describeKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return kind.String()
+	}
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// errorPaths returns the field paths of the FieldErrors joined in err
+func errorPaths(err error) []string {
+	var paths []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			paths = append(paths, errorPaths(err)...)
+		}
+		return paths
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return []string{fieldErr.Path}
+	}
+	if err != nil {
+		return []string{"<" + err.Error() + ">"}
+	}
+	return nil
+}
+
+func TestDecodeStrictErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "valid", input: `{"parallel": 4, "dry_run": true}`},
+		{name: "unknown field", input: `{"paralel": 4}`, want: []string{"params.paralel"}},
+		{name: "unknown nested field", input: `{"presign": {"expires": "1h", "expiry": "1h"}}`, want: []string{"params.presign.expiry"}},
+		{
+			name:  "all problems at once",
+			input: `{"retries": "many", "dry_run": "maybe", "extra": 1}`,
+			want:  []string{"params.dry_run", "params.extra", "params.retries"},
+		},
+		{name: "wrong type", input: `{"cache_dir": 5}`, want: []string{"params.cache_dir"}},
+		{name: "list item", input: `{"paths": ["a", 5]}`, want: []string{"params.paths[1]"}},
+		{name: "fractional integer", input: `{"retries": 1.5}`, want: []string{"params.retries"}},
+		{name: "negative duration", input: `{"progress_interval": "-5s"}`, want: []string{"params.progress_interval"}},
+		{name: "bad size unit", input: `{"range_size": "64MiBs"}`, want: []string{"params.range_size"}},
+		{name: "bad bandwidth unit", input: `{"max_bandwidth": "100MiB/h"}`, want: []string{"params.max_bandwidth"}},
+		{name: "bad parallel", input: `{"parallel": "fast"}`, want: []string{"params.parallel"}},
+		{name: "negative parallel", input: `{"parallel": -2}`, want: []string{"params.parallel"}},
+		{name: "not an object", input: `[]`, want: []string{"params"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params InParams
+			got := errorPaths(json.Unmarshal([]byte(tt.input), &params))
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("error paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeStrictNestedUnknownField(t *testing.T) {
+	var params OutParams
+	err := json.Unmarshal([]byte(`{"promote_from": {"dir": "release", "drir": "x"}}`), &params)
+	if got := errorPaths(err); !slices.Equal(got, []string{"params.promote_from.drir"}) {
+		t.Errorf("error paths = %v", got)
+	}
+
+	var source Source
+	err = json.Unmarshal([]byte(`{"bucket": "b", "max_version": 3, "part_size": "1KB"}`), &source)
+	if got := errorPaths(err); !slices.Equal(got, []string{"source.max_version"}) {
+		t.Errorf("error paths = %v", got)
+	}
+	if source.PartSize != 1000 {
+		t.Errorf("embedded part_size = %d", source.PartSize)
+	}
+}
+
+func TestDecodeStrictValues(t *testing.T) {
+	input := `{
+		"parallel": "8",
+		"dry_run": "yes",
+		"retries": "3",
+		"progress_interval": 30,
+		"range_threshold": "1GiB",
+		"range_size": 1048576,
+		"max_bandwidth": "10MiB/s",
+		"max_requests_per_second": "20",
+		"preserve_attributes": "0"
+	}`
+
+	var params InParams
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"parallel", params.Parallel, Parallel(8)},
+		{"dry_run", params.DryRun, true},
+		{"retries", *params.Retries, 3},
+		{"progress_interval", params.ProgressInterval, 30 * time.Second},
+		{"range_threshold", params.RangeThreshold, Size(1 << 30)},
+		{"range_size", params.RangeSize, Size(1 << 20)},
+		{"max_bandwidth", params.MaxBandwidth, Bandwidth(10 << 20)},
+		{"max_requests_per_second", params.MaxRequestsPerSecond, 20},
+		{"preserve_attributes", params.PreserveAttributes, false},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Parallel
+		wantErr bool
+	}{
+		{input: `4`, want: 4},
+		{input: `"16"`, want: 16},
+		{input: `0`, want: 0},
+		{input: `"auto"`, want: ParallelAuto},
+		{input: `" AUTO "`, want: ParallelAuto},
+		{input: `-1`, wantErr: true},
+		{input: `"automatic"`, wantErr: true},
+		{input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var p Parallel
+			err := json.Unmarshal([]byte(tt.input), &p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && p != tt.want {
+				t.Errorf("got %d, want %d", p, tt.want)
+			}
+		})
+	}
+
+	data, _ := json.Marshal(ParallelAuto)
+	if string(data) != `"auto"` {
+		t.Errorf("ParallelAuto marshals to %s", data)
+	}
+}
+
+func TestSizeAndBandwidth(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: `"64MiB"`, want: 64 << 20},
+		{input: `"1GB"`, want: 1000 * 1000 * 1000},
+		{input: `" 5 MiB "`, want: 5 << 20},
+		{input: `1024`, want: 1024},
+		{input: `"1024"`, want: 1024},
+		{input: `"64 parsecs"`, wantErr: true},
+		{input: `-1`, wantErr: true},
+		{input: `1.5`, wantErr: true},
+		{input: `"99999999EiB"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := parseSize([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && n != tt.want {
+				t.Errorf("parseSize = %d, want %d", n, tt.want)
+			}
+
+			var b Bandwidth
+			err = json.Unmarshal([]byte(tt.input), &b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bandwidth error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && int64(b) != tt.want {
+				t.Errorf("Bandwidth = %d, want %d", b, tt.want)
+			}
+		})
+	}
+
+	var b Bandwidth
+	if err := json.Unmarshal([]byte(`"100MiB/s"`), &b); err != nil || b != 100<<20 {
+		t.Errorf("100MiB/s = %d, %v", b, err)
+	}
+}
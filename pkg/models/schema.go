@@ -0,0 +1,227 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) document or subschema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// schemaProvider is implemented by types whose JSON form is not derived
+// from their Go fields
+type schemaProvider interface {
+	JSONSchema() *Schema
+}
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	schemaProviderType = reflect.TypeFor[schemaProvider]()
+)
+
+// ResourceSchema returns the JSON Schema of the resource configuration. The
+// source, version and params are described under $defs, together with the
+// check, in and out requests that combine them. Fields without omitempty
+// are required, and the lenient forms accepted by DecodeStrict are allowed.
+func ResourceSchema() *Schema {
+	ref := func(name string) *Schema { return &Schema{Ref: "#/$defs/" + name} }
+	request := func(description string, props map[string]*Schema, required ...string) *Schema {
+		return &Schema{
+			Description:          description,
+			Type:                 "object",
+			Properties:           props,
+			Required:             required,
+			AdditionalProperties: true,
+		}
+	}
+
+	return &Schema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		ID:          "https://github.com/zinc-sig/minio-resource/schema.json",
+		Title:       "minio-resource",
+		Description: "Configuration of the Minio Concourse resource",
+		Defs: map[string]*Schema{
+			"source":     schemaFor(reflect.TypeFor[Source]()),
+			"version":    schemaFor(reflect.TypeFor[Version]()),
+			"in_params":  schemaFor(reflect.TypeFor[InParams]()),
+			"out_params": schemaFor(reflect.TypeFor[OutParams]()),
+			"check_request": request("Input of the check script", map[string]*Schema{
+				"source":  ref("source"),
+				"version": ref("version"),
+			}, "source"),
+			"in_request": request("Input of the in script", map[string]*Schema{
+				"source":  ref("source"),
+				"version": ref("version"),
+				"params":  ref("in_params"),
+			}, "source", "version"),
+			"out_request": request("Input of the out script", map[string]*Schema{
+				"source": ref("source"),
+				"params": ref("out_params"),
+			}, "source"),
+		},
+	}
+}
+
+// schemaFor derives the schema of a Go type from its JSON encoding
+func schemaFor(t reflect.Type) *Schema {
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).JSONSchema()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{OneOf: []*Schema{
+			{Type: "string", Pattern: `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`, Description: "Duration such as 30s or 1h30m"},
+			{Type: "number", Minimum: ptr(0.0), Description: "Duration in seconds"},
+		}}
+	case t == sizeType:
+		return &Schema{OneOf: []*Schema{
+			{Type: "string", Pattern: `^[0-9]+(\.[0-9]+)? ?([kKmMgGtTpPeE]i?)?[bB]?$`, Description: "Size such as 64MiB or 1GB"},
+			{Type: "integer", Minimum: ptr(0.0), Description: "Size in bytes"},
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.Bool:
+		return &Schema{OneOf: []*Schema{
+			{Type: "boolean"},
+			{Type: "string", Enum: []any{"true", "false", "yes", "no", "1", "0"}},
+		}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{OneOf: []*Schema{
+			{Type: "integer"},
+			{Type: "string", Pattern: `^-?[0-9]+$`},
+		}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		addStructProperties(schema, t)
+		return schema
+	}
+	return &Schema{}
+}
+
+// addStructProperties adds the JSON fields of a struct to schema, flattening
+// embedded structs the way encoding/json does
+func addStructProperties(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructProperties(schema, field.Type)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// JSONSchema describes the boolean or object forms of presign
+func (Presign) JSONSchema() *Schema {
+	return &Schema{OneOf: []*Schema{
+		{Type: "boolean"},
+		{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"expires": schemaFor(durationType),
+			},
+			AdditionalProperties: false,
+		},
+	}}
+}
+
+// JSONSchema describes the directory or object forms of promote_from
+func (PromoteFrom) JSONSchema() *Schema {
+	type fields PromoteFrom
+	return &Schema{OneOf: []*Schema{
+		{Type: "string", Description: "Directory of a get step"},
+		schemaFor(reflect.TypeFor[fields]()),
+	}}
+}
+
+// JSONSchema describes the boolean and named forms of write_metadata
+func (WriteMetadata) JSONSchema() *Schema {
+	return &Schema{OneOf: []*Schema{
+		schemaFor(reflect.TypeFor[bool]()),
+		{Type: "string", Enum: []any{string(WriteMetadataSidecar), string(WriteMetadataCombined)}},
+	}}
+}
+
+// JSONSchema describes the number and auto forms of parallel
+func (Parallel) JSONSchema() *Schema {
+	return &Schema{OneOf: []*Schema{
+		{Type: "integer", Minimum: ptr(0.0)},
+		{Type: "string", Pattern: `^[0-9]+$`},
+		{Type: "string", Enum: []any{"auto"}, Description: "Adapt to the observed throughput"},
+	}}
+}
+
+// JSONSchema describes the string and number forms of a bandwidth
+func (Bandwidth) JSONSchema() *Schema {
+	return &Schema{OneOf: []*Schema{
+		{Type: "string", Pattern: `^[0-9]+(\.[0-9]+)? ?([kKmMgGtTpPeE]i?)?[bB]?(/s)?$`, Description: "Bandwidth such as 100MiB/s"},
+		{Type: "integer", Minimum: ptr(0.0), Description: "Bytes per second"},
+	}}
+}
+
+// JSONSchema lists the log levels
+func (LogLevel) JSONSchema() *Schema {
+	return &Schema{Type: "string", Enum: []any{"debug", "info", "warn", "error"}}
+}
+
+// JSONSchema lists the log formats
+func (LogFormat) JSONSchema() *Schema {
+	return &Schema{Type: "string", Enum: []any{"text", "json"}}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// TransferStats summarises a download or upload. It is returned as
+// metadata, and in writes it to .resource_stats.json for graphing.
+type TransferStats struct {
+	Files       int            `json:"files"`
+	FailedFiles int            `json:"failed_files"`
+	CachedFiles int            `json:"cached_files"`
+	Bytes       int64          `json:"bytes"`
+	CachedBytes int64          `json:"cached_bytes"`
+	Duration    float64        `json:"duration_seconds"`
+	Throughput  float64        `json:"throughput_bytes_per_second"`
+	LargestFile string         `json:"largest_file,omitempty"`
+	LargestSize int64          `json:"largest_file_bytes"`
+	Retries     int            `json:"retries"`
+	Errors      map[string]int `json:"errors"`
+}
+
+// Add records a file. Only successful files count towards the bytes, and
+// errorClasses holds the class of every failed attempt.
+func (s *TransferStats) Add(path string, size int64, failed bool, retries int, errorClasses []string) {
+	if s.Errors == nil {
+		s.Errors = make(map[string]int)
+	}
+	for _, class := range errorClasses {
+		s.Errors[class]++
+	}
+	s.Retries += retries
+
+	if failed {
+		s.FailedFiles++
+		return
+	}
+	s.Files++
+	s.Bytes += size
+	if size > s.LargestSize || s.LargestFile == "" {
+		s.LargestFile = path
+		s.LargestSize = size
+	}
+}
+
+// AddCached records a file that was restored from the cache instead of
+// being transferred. Its bytes are counted apart, so that the throughput
+// only covers the network.
+func (s *TransferStats) AddCached(path string, size int64) {
+	s.Files++
+	s.CachedFiles++
+	s.CachedBytes += size
+	if size > s.LargestSize || s.LargestFile == "" {
+		s.LargestFile = path
+		s.LargestSize = size
+	}
+}
+
+// Finish records how long the transfer took
+func (s *TransferStats) Finish(duration time.Duration) {
+	s.Duration = duration.Seconds()
+	if s.Duration > 0 {
+		s.Throughput = float64(s.Bytes) / s.Duration
+	}
+	if s.Errors == nil {
+		s.Errors = make(map[string]int)
+	}
+}
+
+// Metadata returns the statistics as human readable metadata
+func (s TransferStats) Metadata() []Metadata {
+	metadata := []Metadata{
+		{
+			Name:  "total_bytes",
+			Value: humanize.IBytes(uint64(s.Bytes)),
+		},
+		{
+			Name:  "duration",
+			Value: time.Duration(s.Duration * float64(time.Second)).Round(time.Millisecond).String(),
+		},
+		{
+			Name:  "throughput",
+			Value: humanize.IBytes(uint64(s.Throughput)) + "/s",
+		},
+		{
+			Name:  "retries",
+			Value: strconv.Itoa(s.Retries),
+		},
+	}
+	if s.CachedFiles > 0 {
+		metadata = append(metadata,
+			Metadata{
+				Name:  "files_cached",
+				Value: strconv.Itoa(s.CachedFiles),
+			},
+			Metadata{
+				Name:  "cached_bytes",
+				Value: humanize.IBytes(uint64(s.CachedBytes)),
+			},
+		)
+	}
+	if s.LargestFile != "" {
+		metadata = append(metadata, Metadata{
+			Name:  "largest_file",
+			Value: fmt.Sprintf("%s (%s)", s.LargestFile, humanize.IBytes(uint64(s.LargestSize))),
+		})
+	}
+
+	classes := make([]string, 0, len(s.Errors))
+	for class := range s.Errors {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		metadata = append(metadata, Metadata{
+			Name:  "errors_" + class,
+			Value: strconv.Itoa(s.Errors[class]),
+		})
+	}
+	return metadata
+}
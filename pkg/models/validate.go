@@ -0,0 +1,330 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	regionPattern     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
+// Validate normalises the source configuration and checks it for problems.
+// An endpoint given as a URL such as https://minio.example.com is reduced to
+// its host, with the scheme applied to use_ssl, and the path prefix is
+// cleaned up. All problems are returned together as FieldErrors naming the
+// offending field.
+func (s *Source) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...any) {
+		errs = append(errs, &FieldError{Path: field, Err: fmt.Errorf(format, args...)})
+	}
+
+	// Connection
+	if s.Endpoint == "" {
+		add("endpoint", "is required")
+	} else if err := s.normalizeEndpoint(); err != nil {
+		add("endpoint", "%v", err)
+	}
+	if s.AccessKey == "" {
+		add("access_key", "is required")
+	}
+	if s.SecretKey == "" {
+		add("secret_key", "is required")
+	}
+	if s.SkipSSLVerification && !s.UseSSLValue() {
+		add("skip_ssl_verification", "cannot be used when use_ssl is false")
+	}
+	if s.Region != "" && !regionPattern.MatchString(s.Region) {
+		add("region", "%q must contain only lowercase letters, numbers and hyphens, e.g. us-east-1", s.Region)
+	}
+
+	// Bucket and prefix
+	if s.Bucket == "" {
+		add("bucket", "is required")
+	} else if err := validateBucketName(s.Bucket); err != nil {
+		add("bucket", "%v", err)
+	}
+	s.PathPrefix = normalizePrefix(s.PathPrefix)
+	if s.CacheMaxSize < 0 {
+		add("cache_max_size", "must not be negative")
+	}
+
+	// Check options
+	if s.MaxVersions < 0 {
+		add("max_versions", "must not be negative")
+	}
+	if _, err := s.CheckSinceTime(time.Now()); err != nil {
+		add("check_since", "%v", err)
+	}
+	if s.InitialPath != "" && s.InitialVersion != nil {
+		add("initial_path", "cannot be used together with initial_version")
+	}
+	if s.InitialPath != "" && !underPrefix(s.InitialPath, s.PathPrefix) {
+		add("initial_path", "%q is outside path_prefix %q", s.InitialPath, s.PathPrefix)
+	}
+	if s.InitialVersion != nil && !underPrefix(s.InitialVersion.Path, s.PathPrefix) {
+		add("initial_version.path", "%q is outside path_prefix %q", s.InitialVersion.Path, s.PathPrefix)
+	}
+	if s.InitialVersion != nil && s.InitialVersion.LastModified.IsZero() && !s.OrderedKeys {
+		// Without it the cursor sorts before every object, so the first check
+		// would return everything
+		add("initial_version.last_modified", "is required unless ordered_keys is set")
+	}
+
+	// Upload options
+	s.UploadParams.validate(add)
+	s.LimitParams.validate(add)
+
+	return errors.Join(errs...)
+}
+
+// validate checks the upload options, reporting problems through add
+func (u UploadParams) validate(add func(field, format string, args ...any)) {
+	if u.PartSize != 0 && (u.PartSize < MinPartSize || u.PartSize > MaxPartSize) {
+		add("part_size", "must be between 5MiB and 5GiB")
+	}
+	if u.UploadThreads < 0 {
+		add("upload_threads", "must not be negative")
+	}
+	if u.DisableMultipart && (u.PartSize != 0 || u.UploadThreads != 0) {
+		add("disable_multipart", "cannot be used together with part_size or upload_threads")
+	}
+}
+
+// validate checks the limits, reporting problems through add
+func (l LimitParams) validate(add func(field, format string, args ...any)) {
+	if l.MaxBandwidth != 0 && l.MaxBandwidth < minBandwidth {
+		add("max_bandwidth", "must be at least 1KiB/s")
+	}
+	if l.MaxRequestsPerSecond < 0 {
+		add("max_requests_per_second", "must not be negative")
+	}
+}
+
+// Validate checks the in params for problems, returned together as
+// FieldErrors
+func (p *InParams) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...any) {
+		errs = append(errs, &FieldError{Path: field, Err: fmt.Errorf(format, args...)})
+	}
+
+	if p.Retries != nil && *p.Retries < 0 {
+		add("retries", "must not be negative")
+	}
+	if p.RangeSize != 0 && p.RangeSize < minRangeSize {
+		add("range_size", "must be at least 1MiB")
+	}
+	if p.Prefix != "" && len(p.Paths) > 0 {
+		add("prefix", "cannot be used together with paths")
+	}
+	for i, pattern := range p.Paths {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			add(fmt.Sprintf("paths[%d]", i), "%q is not a valid key or glob", pattern)
+		}
+	}
+	p.Prefix = normalizePrefix(p.Prefix)
+	if p.Prefix != "" && !strings.HasSuffix(p.Prefix, "/") {
+		p.Prefix += "/"
+	}
+	p.LimitParams.validate(add)
+	return errors.Join(errs...)
+}
+
+// Validate normalises the out params and checks that the options given fit
+// the action. Problems are returned together as FieldErrors.
+func (p *OutParams) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...any) {
+		errs = append(errs, &FieldError{Path: field, Err: fmt.Errorf(format, args...)})
+	}
+
+	if p.Retries != nil && *p.Retries < 0 {
+		add("retries", "must not be negative")
+	}
+	p.UploadParams.validate(add)
+	p.LimitParams.validate(add)
+
+	if p.PromoteFrom.IsSet() {
+		p.validatePromoteFrom(add)
+		return errors.Join(errs...)
+	}
+
+	action := p.ActionValue()
+	switch action {
+	case ActionUpload:
+		if len(p.Paths) > 0 {
+			add("paths", "only applies to the delete, move and copy actions")
+		}
+		if p.ToBucket != "" || p.ToPrefix != "" {
+			add("action", "upload does not take to_bucket or to_prefix")
+		}
+		return errors.Join(errs...)
+	case ActionDelete, ActionMove, ActionCopy:
+	default:
+		add("action", "%q must be one of upload, delete, move or copy", p.Action)
+		return errors.Join(errs...)
+	}
+
+	if p.File != "" {
+		add("file", "only applies to the upload action, use paths with %s", action)
+	}
+	if p.UploadEnabled {
+		add("upload_enabled", "only applies to the upload action")
+	}
+	if p.Presign.Enabled {
+		add("presign", "only applies to the upload action")
+	}
+	if p.PreserveAttributes {
+		add("preserve_attributes", "only applies to the upload action")
+	}
+	if len(p.Paths) == 0 {
+		add("paths", "is required for the %s action", action)
+	}
+	for i, pattern := range p.Paths {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			add(fmt.Sprintf("paths[%d]", i), "%q is not a valid key or glob", pattern)
+		}
+	}
+
+	if action == ActionDelete {
+		if p.ToBucket != "" || p.ToPrefix != "" {
+			add("action", "delete does not take to_bucket or to_prefix")
+		}
+		return errors.Join(errs...)
+	}
+
+	if p.ToBucket == "" && p.ToPrefix == "" {
+		add("to_prefix", "to_prefix or to_bucket is required for the %s action", action)
+	}
+	if p.ToBucket != "" {
+		if err := validateBucketName(p.ToBucket); err != nil {
+			add("to_bucket", "%v", err)
+		}
+	}
+	p.ToPrefix = normalizePrefix(p.ToPrefix)
+
+	return errors.Join(errs...)
+}
+
+// validatePromoteFrom checks promote_from and that it is not combined with
+// the options of other actions
+func (p *OutParams) validatePromoteFrom(add func(field, format string, args ...any)) {
+	conflicts := []struct {
+		field string
+		set   bool
+	}{
+		{"action", p.Action != ""},
+		{"upload_enabled", p.UploadEnabled},
+		{"file", p.File != ""},
+		{"paths", len(p.Paths) > 0},
+		{"to_bucket", p.ToBucket != ""},
+		{"to_prefix", p.ToPrefix != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			add(conflict.field, "cannot be used together with promote_from")
+		}
+	}
+
+	promote := &p.PromoteFrom
+	if promote.Dir != "" && promote.Path != "" {
+		add("promote_from.dir", "cannot be used together with promote_from.path")
+	}
+	if promote.Dir != "" && promote.ETag != "" {
+		add("promote_from.etag", "is read from the version file when promote_from.dir is used")
+	}
+	if promote.Bucket != "" {
+		if err := validateBucketName(promote.Bucket); err != nil {
+			add("promote_from.bucket", "%v", err)
+		}
+	}
+	promote.Prefix = normalizePrefix(promote.Prefix)
+	if promote.Path != "" && !underPrefix(promote.Path, promote.Prefix) {
+		add("promote_from.prefix", "%q is not a prefix of path %q", promote.Prefix, promote.Path)
+	}
+}
+
+// normalizeEndpoint turns an endpoint given as a URL into a host and port,
+// setting use_ssl from its scheme
+func (s *Source) normalizeEndpoint() error {
+	endpoint := strings.TrimSuffix(strings.TrimSpace(s.Endpoint), "/")
+
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid URL: %w", s.Endpoint, err)
+		}
+		if u.Path != "" || u.RawQuery != "" || u.User != nil {
+			return fmt.Errorf("%q must be a host and optional port, without a path, query or credentials", s.Endpoint)
+		}
+
+		var useSSL bool
+		switch u.Scheme {
+		case "https":
+			useSSL = true
+		case "http":
+			useSSL = false
+		default:
+			return fmt.Errorf("%q has unsupported scheme %q, use a bare host such as minio.example.com", s.Endpoint, u.Scheme)
+		}
+		if s.UseSSL != nil && *s.UseSSL != useSSL {
+			return fmt.Errorf("%q conflicts with use_ssl: %t", s.Endpoint, *s.UseSSL)
+		}
+
+		s.UseSSL = &useSSL
+		endpoint = u.Host
+	}
+
+	if strings.Contains(endpoint, "/") {
+		return fmt.Errorf("%q must be a host and optional port, e.g. minio.example.com:9000", s.Endpoint)
+	}
+	if strings.Contains(endpoint, ":") {
+		if _, port, err := net.SplitHostPort(endpoint); err != nil || port == "" {
+			return fmt.Errorf("%q has an invalid port", s.Endpoint)
+		}
+	}
+
+	s.Endpoint = endpoint
+	return nil
+}
+
+// validateBucketName applies the S3 bucket naming rules
+func validateBucketName(bucket string) error {
+	switch {
+	case !bucketNamePattern.MatchString(bucket):
+		return fmt.Errorf("%q must be 3-63 characters of lowercase letters, numbers, dots and hyphens, starting and ending with a letter or number", bucket)
+	case strings.Contains(bucket, ".."):
+		return fmt.Errorf("%q must not contain consecutive dots", bucket)
+	case net.ParseIP(bucket) != nil:
+		return fmt.Errorf("%q must not be formatted as an IP address", bucket)
+	}
+	return nil
+}
+
+// underPrefix reports whether key lies under the directory prefix, so that
+// "data" holds "data/x" but not "database/x". Every key is under an empty
+// prefix.
+func underPrefix(key, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	return strings.HasPrefix(key, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// normalizePrefix removes leading and repeated slashes from a path prefix
+func normalizePrefix(prefix string) string {
+	prefix = strings.TrimLeft(strings.TrimSpace(prefix), "/")
+	for strings.Contains(prefix, "//") {
+		prefix = strings.ReplaceAll(prefix, "//", "/")
+	}
+	return prefix
+}
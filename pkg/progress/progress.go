@@ -0,0 +1,205 @@
+// Package progress reports the progress of long transfers, so that builds
+// moving many gigabytes do not sit silent for minutes at a time.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Options configures progress reporting
+type Options struct {
+	// Interval between reports; zero disables reporting
+	Interval time.Duration
+	// Terminal, if set, receives a single status line that is redrawn in
+	// place instead of log messages
+	Terminal io.Writer
+}
+
+// IsTerminal reports whether f is an interactive terminal. Concourse does
+// not attach one, so progress is logged there instead of redrawn.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Tracker counts the bytes and files of a transfer and reports them
+// periodically until Stop is called. It is safe for concurrent use.
+type Tracker struct {
+	logger *slog.Logger
+	action string
+	opts   Options
+	start  time.Time
+
+	totalFiles atomic.Int64
+	totalBytes atomic.Int64
+	files      atomic.Int64
+	bytes      atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Start begins reporting a transfer of totalFiles files and totalBytes
+// bytes. Action names the transfer in reports, e.g. "Downloading". Totals
+// that are not known up front can be added with Expect.
+func Start(logger *slog.Logger, action string, totalFiles int, totalBytes int64, opts Options) *Tracker {
+	t := &Tracker{
+		logger: logger,
+		action: action,
+		opts:   opts,
+		start:  time.Now(),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	t.Expect(totalFiles, totalBytes)
+
+	if opts.Interval <= 0 {
+		close(t.done)
+		return t
+	}
+
+	go t.run()
+	return t
+}
+
+// Expect adds files and bytes to the totals, for transfers that start
+// before their listing is complete
+func (t *Tracker) Expect(files int, bytes int64) {
+	t.totalFiles.Add(int64(files))
+	t.totalBytes.Add(bytes)
+}
+
+// Bytes returns the bytes transferred so far
+func (t *Tracker) Bytes() int64 {
+	return t.bytes.Load()
+}
+
+// Files returns the number of finished files
+func (t *Tracker) Files() int64 {
+	return t.files.Load()
+}
+
+// Add records n transferred bytes
+func (t *Tracker) Add(n int64) {
+	t.bytes.Add(n)
+}
+
+// FileDone records a finished file
+func (t *Tracker) FileDone() {
+	t.files.Add(1)
+}
+
+// Reader returns a reader that records the bytes read through r
+func (t *Tracker) Reader(r io.Reader) io.Reader {
+	return &countingReader{r: r, t: t}
+}
+
+// Counter returns a reader that records the length of every read without
+// returning data, for APIs such as PutObjectOptions.Progress that report
+// progress by reading from a reader
+func (t *Tracker) Counter() *Counter {
+	return &Counter{t: t}
+}
+
+// Stop ends reporting. On a terminal the status line is completed.
+func (t *Tracker) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.report()
+		case <-t.stop:
+			if t.opts.Terminal != nil {
+				t.report()
+				fmt.Fprintln(t.opts.Terminal)
+			}
+			return
+		}
+	}
+}
+
+// report writes the current status
+func (t *Tracker) report() {
+	files := t.files.Load()
+	bytes := t.bytes.Load()
+	totalFiles := t.totalFiles.Load()
+	totalBytes := t.totalBytes.Load()
+	elapsed := time.Since(t.start)
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(bytes) / elapsed.Seconds()
+	}
+
+	percent := 100.0
+	if totalBytes > 0 {
+		percent = float64(bytes) / float64(totalBytes) * 100
+	}
+
+	eta := "unknown"
+	if rate > 0 && bytes < totalBytes {
+		remaining := float64(totalBytes-bytes) / rate
+		eta = time.Duration(remaining * float64(time.Second)).Round(time.Second).String()
+	} else if bytes >= totalBytes {
+		eta = "0s"
+	}
+
+	byteProgress := fmt.Sprintf("%s/%s", humanize.IBytes(uint64(bytes)), humanize.IBytes(uint64(totalBytes)))
+	fileProgress := fmt.Sprintf("%d/%d", files, totalFiles)
+	throughput := humanize.IBytes(uint64(rate)) + "/s"
+
+	if t.opts.Terminal != nil {
+		fmt.Fprintf(t.opts.Terminal, "\r\033[K%s %s (%.0f%%), %s files, %s, ETA %s",
+			t.action, byteProgress, percent, fileProgress, throughput, eta)
+		return
+	}
+
+	t.logger.Info(t.action, "bytes", byteProgress, "percent", fmt.Sprintf("%.0f%%", percent),
+		"files", fileProgress, "rate", throughput, "eta", eta)
+}
+
+type countingReader struct {
+	r io.Reader
+	t *Tracker
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.t.Add(int64(n))
+	return n, err
+}
+
+// Counter records bytes reported by reading from it, see Tracker.Counter
+type Counter struct {
+	t *Tracker
+	n atomic.Int64
+}
+
+func (c *Counter) Read(p []byte) (int, error) {
+	c.n.Add(int64(len(p)))
+	c.t.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Undo takes back the bytes counted so far, for an attempt that failed
+func (c *Counter) Undo() {
+	c.t.Add(-c.n.Swap(0))
+}
@@ -0,0 +1,210 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+// Check returns the current version followed by the versions after it
+func Check(ctx context.Context, request models.CheckRequest) (models.CheckResponse, error) {
+	// Connect to the bucket
+	logger := newLogger(request.Source, "")
+	client, err := connect(ctx, &request.Source, request.Source.LimitParams, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the check window
+	since, err := request.Source.CheckSinceTime(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid source configuration: check_since: %w", err)
+	}
+
+	// Find versions newer than the current one
+	versions, err := checkByListing(ctx, client, request, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	logger.Debug("Check complete", "current", request.Version.Path, "versions", len(versions))
+
+	return models.CheckResponse(versions), nil
+}
+
+// checkByListing finds new versions by listing the bucket, ordered by key or
+// by time depending on the source configuration
+func checkByListing(ctx context.Context, client *minioClient.Client, request models.CheckRequest, since time.Time) ([]models.Version, error) {
+	if request.Source.OrderedKeys {
+		return checkByKey(ctx, client, request, since)
+	}
+	return checkByTime(ctx, client, request, since)
+}
+
+// checkByTime lists the whole prefix and returns the versions after the
+// current one in (last modified, path, etag) order
+func checkByTime(ctx context.Context, client *minioClient.Client, request models.CheckRequest, since time.Time) ([]models.Version, error) {
+	// Convert objects to versions
+	var all []models.Version
+	err := client.WalkObjects(ctx, minioClient.ListOptions{}, func(obj minioClient.ObjectInfo) error {
+		// Skip objects older than check_since
+		if !obj.LastModified.Before(since) {
+			all = append(all, toVersion(obj))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versionsAfter(all, request, client.Logger()), nil
+}
+
+// versionsAfter sorts listed versions into cursor order and returns those
+// after the current version, or after the initial cursor on the first check
+func versionsAfter(all []models.Version, request models.CheckRequest, logger *slog.Logger) []models.Version {
+	// Sort versions by (last modified, path, etag), oldest first as per Concourse requirements
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Compare(all[j]) < 0
+	})
+
+	// Determine the cursor to resume from
+	cursor := request.Version
+	if cursor.Path == "" {
+		cursor = initialCursor(request.Source, all, logger)
+	}
+
+	// Emit every version after the cursor, with the cursor itself first
+	if cursor.Path == "" {
+		return newest(all, request.Source.MaxVersions)
+	}
+
+	found := false
+	versions := make([]models.Version, 0, len(all))
+	for _, version := range all {
+		if version.Path == cursor.Path && version.ETag == cursor.ETag {
+			found = true
+			continue
+		}
+
+		// A modified object at the cursor path is always new, even if its
+		// timestamp does not sort after the cursor
+		if version.Compare(cursor) > 0 || version.Path == cursor.Path {
+			versions = append(versions, version)
+		}
+	}
+
+	// Later versions are left to the following checks, so none are skipped
+	versions = oldest(versions, request.Source.MaxVersions)
+
+	// The current version goes first; Concourse expects it to be echoed back
+	if found || request.Version.Path != "" {
+		versions = append([]models.Version{cursor}, versions...)
+	}
+	return versions
+}
+
+// checkByKey lists only the keys after the current one, for buckets whose
+// keys sort in creation order. With a current version the listing stops
+// after max_versions keys, so a backlog is consumed over several checks.
+func checkByKey(ctx context.Context, client *minioClient.Client, request models.CheckRequest, since time.Time) ([]models.Version, error) {
+	// Determine the cursor to resume from
+	cursor := request.Version
+	found := cursor.Path != ""
+	if cursor.Path == "" {
+		if request.Source.InitialVersion != nil {
+			cursor = *request.Source.InitialVersion
+		} else if request.Source.InitialPath != "" {
+			cursor = models.Version{Path: request.Source.InitialPath}
+		}
+
+		if cursor.Path != "" {
+			obj, err := client.StatObject(ctx, cursor.Path)
+			if err == nil && (cursor.ETag == "" || cursor.ETag == obj.ETag) {
+				cursor = toVersion(obj)
+				found = true
+			}
+		}
+	}
+
+	limit := request.Source.MaxVersions
+	var versions []models.Version
+	err := client.WalkObjects(ctx, minioClient.ListOptions{StartAfter: cursor.Path}, func(obj minioClient.ObjectInfo) error {
+		// Skip objects older than check_since
+		if obj.LastModified.Before(since) {
+			return nil
+		}
+
+		versions = append(versions, toVersion(obj))
+		if limit <= 0 {
+			return nil
+		}
+		if cursor.Path != "" && len(versions) == limit {
+			return minioClient.ErrStopListing
+		}
+
+		// Without a cursor only the newest keys are kept
+		if len(versions) > limit {
+			versions = versions[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The current version goes first; Concourse expects it to be echoed back
+	if found {
+		versions = append([]models.Version{cursor}, versions...)
+	}
+	return versions, nil
+}
+
+// toVersion converts a listed object to a version, truncating the
+// modification time to the precision that survives a round trip through
+// Concourse
+func toVersion(obj minioClient.ObjectInfo) models.Version {
+	return models.Version{
+		Path:         obj.Path,
+		ETag:         obj.ETag,
+		LastModified: obj.LastModified.Truncate(time.Second),
+	}
+}
+
+// initialCursor returns the version the first check starts from, as set by
+// initial_version or initial_path, or an empty version to emit everything.
+func initialCursor(source models.Source, versions []models.Version, logger *slog.Logger) models.Version {
+	if source.InitialVersion != nil {
+		return *source.InitialVersion
+	}
+	if source.InitialPath != "" {
+		for _, version := range versions {
+			if version.Path == source.InitialPath {
+				return version
+			}
+		}
+		logger.Warn("initial_path not found, returning all versions", "initial_path", source.InitialPath)
+	}
+	return models.Version{}
+}
+
+// newest returns the last limit versions of a sorted slice, or all of them
+// when limit is not positive.
+func newest(versions []models.Version, limit int) []models.Version {
+	if limit > 0 && len(versions) > limit {
+		return versions[len(versions)-limit:]
+	}
+	return versions
+}
+
+// oldest returns the first limit versions of a sorted slice, or all of them
+// when limit is not positive.
+func oldest(versions []models.Version, limit int) []models.Version {
+	if limit > 0 && len(versions) > limit {
+		return versions[:limit]
+	}
+	return versions
+}
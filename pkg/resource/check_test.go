@@ -0,0 +1,131 @@
+package resource
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zinc-sig/minio-resource/pkg/logging"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+func TestVersionsAfter(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(path, etag string, seconds int) models.Version {
+		return models.Version{Path: path, ETag: etag, LastModified: base.Add(time.Duration(seconds) * time.Second)}
+	}
+	listing := func() []models.Version {
+		return []models.Version{
+			at("c", "3", 2),
+			at("a", "1", 0),
+			at("d", "4", 2),
+			at("b", "2", 1),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		check    models.CheckParams
+		current  models.Version
+		listing  []models.Version
+		expected []string
+	}{
+		{
+			name:     "first check returns everything oldest first",
+			listing:  listing(),
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "cursor is echoed before newer versions",
+			current:  at("b", "2", 1),
+			listing:  listing(),
+			expected: []string{"b", "c", "d"},
+		},
+		{
+			name:     "equal timestamps are ordered by path",
+			current:  at("c", "3", 2),
+			listing:  listing(),
+			expected: []string{"c", "d"},
+		},
+		{
+			name:     "latest version returns only itself",
+			current:  at("d", "4", 2),
+			listing:  listing(),
+			expected: []string{"d"},
+		},
+		{
+			name:     "deleted cursor is still echoed",
+			current:  at("bb", "9", 1),
+			listing:  listing(),
+			expected: []string{"bb", "c", "d"},
+		},
+		{
+			name:     "modified object at the cursor path is new",
+			current:  at("d", "4", 2),
+			listing:  append(listing()[:3], at("d", "5", 2)),
+			expected: []string{"d", "d"},
+		},
+		{
+			name:    "sub-second listing times match a truncated cursor",
+			current: at("b", "2", 1),
+			listing: []models.Version{
+				at("a", "1", 0),
+				{Path: "b", ETag: "2", LastModified: base.Add(1500 * time.Millisecond)},
+				{Path: "c", ETag: "3", LastModified: base.Add(1200 * time.Millisecond)},
+			},
+			expected: []string{"b", "c"},
+		},
+		{
+			name:     "initial_version seeds the first check",
+			check:    models.CheckParams{InitialVersion: &models.Version{Path: "b", ETag: "2", LastModified: base.Add(time.Second)}},
+			listing:  listing(),
+			expected: []string{"b", "c", "d"},
+		},
+		{
+			name:     "initial_path seeds the first check",
+			check:    models.CheckParams{InitialPath: "c"},
+			listing:  listing(),
+			expected: []string{"c", "d"},
+		},
+		{
+			name:     "missing initial_path returns everything",
+			check:    models.CheckParams{InitialPath: "x"},
+			listing:  listing(),
+			expected: []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "max_versions keeps the newest on the first check",
+			check:    models.CheckParams{MaxVersions: 2},
+			listing:  listing(),
+			expected: []string{"c", "d"},
+		},
+		{
+			name:     "max_versions keeps the oldest after the cursor",
+			check:    models.CheckParams{MaxVersions: 2},
+			current:  at("a", "1", 0),
+			listing:  listing(),
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "max_versions keeps the oldest after initial_path",
+			check:    models.CheckParams{MaxVersions: 1, InitialPath: "b"},
+			listing:  listing(),
+			expected: []string{"b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := models.CheckRequest{Source: models.Source{CheckParams: tt.check}, Version: tt.current}
+			versions := versionsAfter(tt.listing, request, logging.Discard())
+
+			var paths []string
+			for _, version := range versions {
+				paths = append(paths, version.Path)
+			}
+			if !slices.Equal(paths, tt.expected) {
+				t.Errorf("versionsAfter() = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+// In downloads all objects with the configured path prefix to destination
+func In(ctx context.Context, request models.InRequest, destination string) (models.InResponse, error) {
+	if err := request.Params.Validate(); err != nil {
+		return models.InResponse{}, fmt.Errorf("invalid params:\n%w", err)
+	}
+
+	// Connect to the bucket
+	logger := newLogger(request.Source, request.Params.LogLevel)
+	client, err := connect(ctx, &request.Source, request.Source.LimitParams.Merge(request.Params.LimitParams), logger)
+	if err != nil {
+		return models.InResponse{}, err
+	}
+
+	// Narrow the download to the prefix or paths params
+	selected := selectObjects(request)
+
+	// List what would be downloaded without writing anything
+	if request.Params.DryRun {
+		return dryRunIn(ctx, client, request, selected, destination)
+	}
+
+	// Determine parallelism from params
+	auto := request.Params.Parallel == models.ParallelAuto
+	parallel := "auto"
+	if !auto {
+		parallel = "5"
+		if request.Params.Parallel > 0 {
+			parallel = strconv.Itoa(int(request.Params.Parallel))
+		}
+	}
+
+	// Log what we're doing
+	logger.Info("Downloading all files", "bucket", request.Source.Bucket,
+		"path_prefix", request.Source.PathPrefix, "prefix", request.Params.Prefix,
+		"paths", request.Params.Paths, "parallel", parallel)
+
+	// Keep unchanged objects in the cache directory between gets
+	cacheDir := request.Params.CacheDir
+	if cacheDir == "" {
+		cacheDir = request.Source.CacheDir
+	}
+
+	// Download all objects
+	start := time.Now()
+	results, err := client.DownloadAllObjects(ctx, destination, minioClient.DownloadOptions{
+		Parallel:     max(int(request.Params.Parallel), 0),
+		AutoParallel: auto,
+		Progress:     progressOptions(request.Source, request.Params.ProgressInterval, logger),
+		Retries:      request.Params.RetriesValue(),
+		CacheDir:     cacheDir,
+		CacheMaxSize: int64(request.Source.CacheMaxSizeValue()),
+
+		RangeThreshold: int64(request.Params.RangeThresholdValue()),
+		RangeSize:      int64(request.Params.RangeSizeValue()),
+
+		PreserveAttributes: request.Params.PreserveAttributes,
+		Describe:           request.Params.WriteMetadata != "",
+
+		Prefix:  selected.prefix,
+		Filter:  selected.filter(),
+		Flatten: request.Params.Flatten,
+	})
+	if ctx.Err() != nil {
+		return models.InResponse{}, fmt.Errorf("download aborted: %w", context.Cause(ctx))
+	}
+	if err != nil {
+		return models.InResponse{}, fmt.Errorf("failed to download objects: %w", err)
+	}
+	if err := selected.unmatched(); err != nil {
+		return models.InResponse{}, err
+	}
+
+	// Check for errors and collect metadata
+	var metadata []models.Metadata
+	var stats models.TransferStats
+	successCount := 0
+	failCount := 0
+
+	for _, result := range results {
+		if result.Cached {
+			stats.AddCached(result.Path, result.Size)
+		} else {
+			addTransfer(&stats, result.Path, result.Size, result.Retried, result.Error)
+		}
+		if result.Error != nil {
+			logger.Warn("Download failed", "key", result.Path, "error", result.Error)
+			failCount++
+		} else {
+			successCount++
+		}
+	}
+
+	// Add download statistics as metadata
+	metadata = append(metadata,
+		models.Metadata{
+			Name:  "files_downloaded",
+			Value: strconv.Itoa(successCount),
+		},
+		models.Metadata{
+			Name:  "files_failed",
+			Value: strconv.Itoa(failCount),
+		},
+		models.Metadata{
+			Name:  "path_prefix",
+			Value: request.Source.PathPrefix,
+		},
+	)
+	metadata = append(metadata, selected.metadata()...)
+	stats.Finish(time.Since(start))
+	metadata = append(metadata, stats.Metadata()...)
+
+	// Write version file (for debugging and tracking)
+	versionFile := filepath.Join(destination, ".resource_version.json")
+	versionData, _ := json.MarshalIndent(request.Version, "", "  ")
+	if err := os.WriteFile(versionFile, versionData, 0644); err != nil {
+		logger.Warn("Failed to write version file", "error", err)
+	}
+
+	// Write transfer statistics for tasks and graphs
+	statsData, _ := json.MarshalIndent(stats, "", "  ")
+	if err := os.WriteFile(filepath.Join(destination, ".resource_stats.json"), statsData, 0644); err != nil {
+		logger.Warn("Failed to write stats file", "error", err)
+	}
+
+	// Write presigned download URLs for the downloaded files
+	if request.Params.Presign.Enabled {
+		urls, err := presignResults(ctx, client, results, request.Params.Presign.ExpiresValue())
+		if err != nil {
+			return models.InResponse{}, fmt.Errorf("failed to presign urls: %w", err)
+		}
+
+		urlsData, _ := json.MarshalIndent(urls, "", "  ")
+		if err := os.WriteFile(filepath.Join(destination, ".resource_urls.json"), urlsData, 0644); err != nil {
+			return models.InResponse{}, fmt.Errorf("failed to write urls file: %w", err)
+		}
+		metadata = append(metadata, models.Metadata{
+			Name:  "presigned_urls",
+			Value: strconv.Itoa(len(urls)),
+		})
+	}
+
+	// Write object metadata for tasks
+	if request.Params.WriteMetadata != "" {
+		written, err := writeObjectMetadata(destination, results, request.Params.WriteMetadata)
+		if err != nil {
+			return models.InResponse{}, fmt.Errorf("failed to write object metadata: %w", err)
+		}
+		metadata = append(metadata, models.Metadata{
+			Name:  "objects_described",
+			Value: strconv.Itoa(written),
+		})
+	}
+
+	// If specific version was requested, include its metadata
+	if request.Version.Path != "" {
+		metadata = append(metadata,
+			models.Metadata{
+				Name:  "version_path",
+				Value: request.Version.Path,
+			},
+			models.Metadata{
+				Name:  "version_etag",
+				Value: request.Version.ETag,
+			},
+			models.Metadata{
+				Name:  "version_modified",
+				Value: request.Version.LastModified.Format("2006-01-02 15:04:05"),
+			},
+		)
+	}
+
+	// Check if any downloads failed
+	if failCount > 0 && successCount == 0 {
+		return models.InResponse{}, fmt.Errorf("all downloads failed")
+	}
+
+	// Log summary
+	logger.Info("Download complete", "succeeded", successCount, "failed", failCount,
+		"size", humanize.IBytes(uint64(stats.Bytes)), "duration", time.Since(start), "retries", stats.Retries)
+
+	return models.InResponse{
+		Version:  request.Version,
+		Metadata: metadata,
+	}, nil
+}
+
+// dryRunIn logs the objects In would download and where they would be
+// written, without downloading them
+func dryRunIn(ctx context.Context, client *minioClient.Client, request models.InRequest, selected selection, destination string) (models.InResponse, error) {
+	var objects []minioClient.ObjectInfo
+	filter := selected.filter()
+	err := client.WalkObjects(ctx, minioClient.ListOptions{Prefix: selected.prefix}, func(object minioClient.ObjectInfo) error {
+		if filter == nil || filter(object) {
+			objects = append(objects, object)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.InResponse{}, fmt.Errorf("failed to list objects: %w", err)
+	}
+	if err := selected.unmatched(); err != nil {
+		return models.InResponse{}, err
+	}
+
+	var total int64
+	logger := client.Logger()
+	logger.Info("Dry run, nothing is written", "objects", len(objects))
+	for _, object := range objects {
+		localPath := client.LocalPath(object.Path)
+		if request.Params.Flatten {
+			localPath = filepath.Base(localPath)
+		}
+		logger.Info("Would download", "key", object.Path, "path", filepath.Join(destination, localPath), "size", humanize.IBytes(uint64(object.Size)))
+		total += object.Size
+	}
+
+	metadata := []models.Metadata{
+		{
+			Name:  "dry_run",
+			Value: "true",
+		},
+		{
+			Name:  "files_planned",
+			Value: strconv.Itoa(len(objects)),
+		},
+		{
+			Name:  "bytes_planned",
+			Value: humanize.IBytes(uint64(total)),
+		},
+		{
+			Name:  "path_prefix",
+			Value: request.Source.PathPrefix,
+		},
+	}
+	return models.InResponse{
+		Version:  request.Version,
+		Metadata: append(metadata, selected.metadata()...),
+	}, nil
+}
+
+// selection is the part of the path prefix that In downloads, either the
+// keys below a sub-prefix or those matching the paths param
+type selection struct {
+	prefix  string
+	paths   []string
+	matcher *keyMatcher
+}
+
+// selectObjects returns the selection of the prefix and paths params. Paths
+// are listed from the literal start they share, so a single key does not
+// list the whole path prefix.
+func selectObjects(request models.InRequest) selection {
+	selected := selection{prefix: request.Params.Prefix, paths: request.Params.Paths}
+	if len(selected.paths) > 0 {
+		selected.matcher = newKeyMatcher(request.Source.PathPrefix, selected.paths)
+		selected.prefix = selected.matcher.listPrefix()
+	}
+	return selected
+}
+
+// filter returns the filter of listed objects, or nil if all are selected
+func (s selection) filter() func(minioClient.ObjectInfo) bool {
+	if s.matcher == nil {
+		return nil
+	}
+	return s.matcher.match
+}
+
+// unmatched fails when one of the paths matched no object
+func (s selection) unmatched() error {
+	if s.matcher == nil {
+		return nil
+	}
+	return s.matcher.unmatched()
+}
+
+// metadata describes the selection for the response
+func (s selection) metadata() []models.Metadata {
+	switch {
+	case s.matcher != nil:
+		return []models.Metadata{{Name: "paths", Value: strings.Join(s.paths, ",")}}
+	case s.prefix != "":
+		return []models.Metadata{{Name: "prefix", Value: s.prefix}}
+	}
+	return nil
+}
+
+// presignResults returns a presigned download URL for every successfully
+// downloaded object
+func presignResults(ctx context.Context, client *minioClient.Client, results []minioClient.DownloadResult, expiry time.Duration) ([]models.PresignedURL, error) {
+	expiresAt := time.Now().Add(expiry).UTC().Truncate(time.Second)
+	urls := make([]models.PresignedURL, 0, len(results))
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+
+		u, err := client.PresignGetObject(ctx, result.Path, expiry)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, models.PresignedURL{
+			Path:      result.LocalPath,
+			Key:       result.Path,
+			URL:       u,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return urls, nil
+}
+
+// writeObjectMetadata writes the metadata of every described object, either
+// to <file>.metadata.json next to each file or as a list to
+// .resource_metadata.json, and returns the number of objects written. No
+// sidecar is written if one would replace a downloaded file.
+func writeObjectMetadata(destination string, results []minioClient.DownloadResult, mode models.WriteMetadata) (int, error) {
+	objects := make([]models.ObjectMetadata, 0, len(results))
+	for _, result := range results {
+		if result.Error == nil && result.Metadata != nil {
+			objects = append(objects, *result.Metadata)
+		}
+	}
+
+	if mode == models.WriteMetadataCombined {
+		data, _ := json.MarshalIndent(objects, "", "  ")
+		return len(objects), os.WriteFile(filepath.Join(destination, ".resource_metadata.json"), data, 0644)
+	}
+
+	// Sidecars must not overwrite downloaded files of the same name
+	downloaded := make(map[string]bool, len(results))
+	for _, result := range results {
+		downloaded[result.LocalPath] = true
+	}
+	for _, object := range objects {
+		if name := object.Path + ".metadata.json"; downloaded[name] {
+			return 0, fmt.Errorf("%s is a downloaded object, use write_metadata: combined instead", name)
+		}
+	}
+
+	for _, object := range objects {
+		data, _ := json.MarshalIndent(object, "", "  ")
+		if err := os.WriteFile(filepath.Join(destination, object.Path+".metadata.json"), data, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(objects), nil
+}
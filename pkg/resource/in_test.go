@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+func TestWriteObjectMetadata(t *testing.T) {
+	result := func(path string) minioClient.DownloadResult {
+		return minioClient.DownloadResult{
+			Path:      "builds/" + path,
+			LocalPath: path,
+			Metadata:  &models.ObjectMetadata{Key: "builds/" + path, Path: path},
+		}
+	}
+
+	t.Run("sidecars next to files", func(t *testing.T) {
+		dir := t.TempDir()
+		written, err := writeObjectMetadata(dir, []minioClient.DownloadResult{result("app.tgz")}, models.WriteMetadataSidecar)
+		if err != nil || written != 1 {
+			t.Fatalf("writeObjectMetadata() = %d, %v", written, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "app.tgz.metadata.json")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("sidecar colliding with a download", func(t *testing.T) {
+		dir := t.TempDir()
+		results := []minioClient.DownloadResult{result("app.tgz"), result("app.tgz.metadata.json")}
+		if _, err := writeObjectMetadata(dir, results, models.WriteMetadataSidecar); err == nil {
+			t.Fatal("expected an error")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "app.tgz.metadata.json")); !os.IsNotExist(err) {
+			t.Errorf("sidecar written over the download: %v", err)
+		}
+	})
+
+	t.Run("combined ignores collisions", func(t *testing.T) {
+		dir := t.TempDir()
+		results := []minioClient.DownloadResult{result("app.tgz"), result("app.tgz.metadata.json")}
+		written, err := writeObjectMetadata(dir, results, models.WriteMetadataCombined)
+		if err != nil || written != 2 {
+			t.Fatalf("writeObjectMetadata() = %d, %v", written, err)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+// manageObjects deletes, moves or copies the objects matching the paths
+// param. Copies and moves are server-side, so nothing is downloaded.
+func manageObjects(ctx context.Context, request models.OutRequest, logger *slog.Logger) (models.OutResponse, error) {
+	params := request.Params
+	action := params.ActionValue()
+
+	targetBucket := params.ToBucket
+	if targetBucket == "" {
+		targetBucket = request.Source.Bucket
+	}
+	if action != models.ActionDelete && targetBucket == request.Source.Bucket && samePrefix(params.ToPrefix, request.Source.PathPrefix) {
+		return models.OutResponse{}, fmt.Errorf("%s would write objects onto themselves, to_prefix must differ from path_prefix", action)
+	}
+
+	// Create Minio client
+	client, err := connect(ctx, &request.Source, request.Source.LimitParams.Merge(request.Params.LimitParams), logger)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	// Find the objects to act on
+	objects, err := matchObjects(ctx, client, request.Source.PathPrefix, params.Paths)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	plan := make([]plannedAction, 0, len(objects))
+	for _, object := range objects {
+		planned := plannedAction{
+			Action: action,
+			Key:    object.Path,
+			Size:   object.Size,
+		}
+		if action != models.ActionDelete {
+			planned.TargetBucket = targetBucket
+			planned.Target = targetKey(params.ToPrefix, request.Source.PathPrefix, object.Path)
+		}
+		plan = append(plan, planned)
+	}
+
+	pathsValue := strings.Join(params.Paths, ",")
+	if params.DryRun {
+		return dryRunOut(logger, plan, models.Metadata{Name: "paths", Value: pathsValue}), nil
+	}
+
+	// Perform the actions, carrying on past failures so that one bad object
+	// does not leave the rest unprocessed
+	var errs []error
+	var lastVersion models.Version
+	done := 0
+	for _, planned := range plan {
+		if action == models.ActionDelete {
+			logger.Debug("Deleting", "key", planned.Key)
+			if err := client.RemoveObject(ctx, planned.Key); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			done++
+			continue
+		}
+
+		logger.Debug("Copying", "key", planned.Key, "target", planned.TargetBucket+"/"+planned.Target)
+		info, err := client.CopyObject(ctx, minioClient.CopySource{Key: planned.Key}, planned.TargetBucket, planned.Target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if action == models.ActionMove {
+			if err := client.RemoveObject(ctx, planned.Key); err != nil {
+				errs = append(errs, fmt.Errorf("copied but could not remove the original: %w", err))
+				continue
+			}
+		}
+		done++
+
+		lastVersion = models.Version{
+			Path:         info.Path,
+			ETag:         info.ETag,
+			LastModified: info.LastModified,
+		}
+	}
+
+	if len(errs) > 0 {
+		return models.OutResponse{}, fmt.Errorf("failed to %s %d of %d objects:\n%w", action, len(errs), len(plan), errors.Join(errs...))
+	}
+
+	if action == models.ActionDelete {
+		lastVersion = models.Version{
+			Path:         "deleted",
+			ETag:         fmt.Sprintf("delete-%d", time.Now().Unix()),
+			LastModified: time.Now(),
+		}
+	}
+
+	logger.Info("Objects processed", "action", action, "count", done)
+
+	return models.OutResponse{
+		Version: lastVersion,
+		Metadata: []models.Metadata{
+			{
+				Name:  "action",
+				Value: action,
+			},
+			{
+				Name:  "files_" + pastTense(action),
+				Value: fmt.Sprintf("%d", done),
+			},
+			{
+				Name:  "paths",
+				Value: pathsValue,
+			},
+		},
+	}, nil
+}
+
+// matchObjects lists the objects whose key, relative to prefix, matches any
+// of the patterns. Every pattern must match at least one object, so a typo
+// fails the build instead of silently doing nothing.
+func matchObjects(ctx context.Context, client *minioClient.Client, prefix string, patterns []string) ([]minioClient.ObjectInfo, error) {
+	matcher := newKeyMatcher(prefix, patterns)
+	var objects []minioClient.ObjectInfo
+
+	err := client.WalkObjects(ctx, minioClient.ListOptions{Prefix: matcher.listPrefix()}, func(object minioClient.ObjectInfo) error {
+		if matcher.match(object) {
+			objects = append(objects, object)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := matcher.unmatched(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// keyMatcher matches object keys, relative to a prefix, against keys or
+// globs in path.Match syntax and remembers which patterns matched
+type keyMatcher struct {
+	prefix   string
+	patterns []string
+	matched  []bool
+}
+
+func newKeyMatcher(prefix string, patterns []string) *keyMatcher {
+	return &keyMatcher{
+		prefix:   prefix,
+		patterns: patterns,
+		matched:  make([]bool, len(patterns)),
+	}
+}
+
+// match reports whether the object matches any of the patterns
+func (m *keyMatcher) match(object minioClient.ObjectInfo) bool {
+	relative := relativeKey(object.Path, m.prefix)
+	found := false
+	for i, pattern := range m.patterns {
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), relative); ok {
+			m.matched[i] = true
+			found = true
+		}
+	}
+	return found
+}
+
+// listPrefix returns the literal start shared by all patterns, so that
+// only the keys that can match are listed
+func (m *keyMatcher) listPrefix() string {
+	var common string
+	for i, pattern := range m.patterns {
+		pattern = strings.TrimPrefix(pattern, "/")
+		if meta := strings.IndexAny(pattern, `*?[\`); meta >= 0 {
+			pattern = pattern[:meta]
+		}
+		if i == 0 {
+			common = pattern
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(pattern) && common[n] == pattern[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return common
+}
+
+// unmatched returns an error naming the first pattern that matched nothing
+func (m *keyMatcher) unmatched() error {
+	for i, pattern := range m.patterns {
+		if !m.matched[i] {
+			return fmt.Errorf("no objects under %q match %q", m.prefix, pattern)
+		}
+	}
+	return nil
+}
+
+// targetKey returns the key that keeps the part of key below fromPrefix
+// below toPrefix. Either prefix may or may not end in a slash.
+func targetKey(toPrefix, fromPrefix, key string) string {
+	return strings.TrimPrefix(path.Join(toPrefix, relativeKey(key, fromPrefix)), "/")
+}
+
+// relativeKey returns the part of key below the directory prefix, without a
+// leading slash. A key that is not under prefix is returned unchanged.
+func relativeKey(key, prefix string) string {
+	if dir := strings.TrimSuffix(prefix, "/"); dir != "" {
+		key = strings.TrimPrefix(key, dir+"/")
+	}
+	return strings.TrimPrefix(key, "/")
+}
+
+// samePrefix reports whether two prefixes name the same directory, with or
+// without a trailing slash
+func samePrefix(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// pastTense names the metadata counter of an action
+func pastTense(action string) string {
+	switch action {
+	case models.ActionDelete:
+		return "deleted"
+	case models.ActionMove:
+		return "moved"
+	default:
+		return "copied"
+	}
+}
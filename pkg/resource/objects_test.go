@@ -0,0 +1,281 @@
+package resource
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+func TestTargetKey(t *testing.T) {
+	tests := []struct {
+		toPrefix, fromPrefix, key string
+		want                      string
+	}{
+		{"processed/", "incoming/", "incoming/a.csv", "processed/a.csv"},
+		{"processed", "incoming/", "incoming/a.csv", "processed/a.csv"},
+		{"processed/", "incoming", "incoming/a.csv", "processed/a.csv"},
+		{"processed", "incoming", "incoming/2024/a.csv", "processed/2024/a.csv"},
+		{"", "incoming/", "incoming/a.csv", "a.csv"},
+		{"processed", "", "a.csv", "processed/a.csv"},
+		{"archive/processed/", "", "2024/a.csv", "archive/processed/2024/a.csv"},
+		{"releases", "builds/42/", "builds/42/app.tgz", "releases/app.tgz"},
+		{"releases/", "builds/42", "builds/42/app.tgz", "releases/app.tgz"},
+		{"", "incoming", "incoming/a/b.txt", "a/b.txt"},
+		{"/", "incoming/", "incoming/a.csv", "a.csv"},
+		{"processed", "build", "build/a.csv", "processed/a.csv"},
+	}
+
+	for _, tt := range tests {
+		if got := targetKey(tt.toPrefix, tt.fromPrefix, tt.key); got != tt.want {
+			t.Errorf("targetKey(%q, %q, %q) = %q, want %q", tt.toPrefix, tt.fromPrefix, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSamePrefix(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"incoming/", "incoming/", true},
+		{"incoming", "incoming/", true},
+		{"incoming/", "incoming", true},
+		{"", "", true},
+		{"incoming", "incoming2", false},
+		{"incoming/", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := samePrefix(tt.a, tt.b); got != tt.want {
+			t.Errorf("samePrefix(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeKey(t *testing.T) {
+	tests := []struct {
+		key, prefix string
+		want        string
+	}{
+		{"incoming/a.csv", "incoming", "a.csv"},
+		{"incoming/a.csv", "incoming/", "a.csv"},
+		{"incoming/2024/a.csv", "incoming", "2024/a.csv"},
+		{"a.csv", "", "a.csv"},
+		{"/a.csv", "", "a.csv"},
+		{"incoming2/a.csv", "incoming", "incoming2/a.csv"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeKey(tt.key, tt.prefix); got != tt.want {
+			t.Errorf("relativeKey(%q, %q) = %q, want %q", tt.key, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestKeyMatcher(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefix        string
+		patterns      []string
+		keys          []string
+		want          []string
+		wantUnmatched bool
+	}{
+		{
+			name:     "exact key",
+			prefix:   "incoming",
+			patterns: []string{"a.csv"},
+			keys:     []string{"incoming/a.csv", "incoming/b.csv"},
+			want:     []string{"incoming/a.csv"},
+		},
+		{
+			name:     "glob",
+			prefix:   "incoming/",
+			patterns: []string{"*.csv"},
+			keys:     []string{"incoming/a.csv", "incoming/b.txt", "incoming/2024/c.csv"},
+			want:     []string{"incoming/a.csv"},
+		},
+		{
+			name:     "leading slash in pattern",
+			prefix:   "incoming",
+			patterns: []string{"/2024/*.csv"},
+			keys:     []string{"incoming/a.csv", "incoming/2024/c.csv"},
+			want:     []string{"incoming/2024/c.csv"},
+		},
+		{
+			name:     "several patterns",
+			prefix:   "",
+			patterns: []string{"a.csv", "b.*"},
+			keys:     []string{"a.csv", "b.txt", "c.csv"},
+			want:     []string{"a.csv", "b.txt"},
+		},
+		{
+			name:          "pattern matching nothing",
+			prefix:        "incoming",
+			patterns:      []string{"a.csv", "missing.csv"},
+			keys:          []string{"incoming/a.csv"},
+			want:          []string{"incoming/a.csv"},
+			wantUnmatched: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := newKeyMatcher(tt.prefix, tt.patterns)
+			var got []string
+			for _, key := range tt.keys {
+				if matcher.match(minioClient.ObjectInfo{Path: key}) {
+					got = append(got, key)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+			if err := matcher.unmatched(); (err != nil) != tt.wantUnmatched {
+				t.Errorf("unmatched() = %v, wantUnmatched %v", err, tt.wantUnmatched)
+			}
+		})
+	}
+}
+
+func TestKeyMatcherListPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{name: "exact key", patterns: []string{"2024/a.csv"}, want: "2024/a.csv"},
+		{name: "glob", patterns: []string{"2024/*.csv"}, want: "2024/"},
+		{name: "leading slash", patterns: []string{"/2024/*.csv"}, want: "2024/"},
+		{name: "shared start", patterns: []string{"2024/01/a.csv", "2024/02/*"}, want: "2024/0"},
+		{name: "nothing shared", patterns: []string{"a.csv", "b.csv"}, want: ""},
+		{name: "leading glob", patterns: []string{"*.csv"}, want: ""},
+		{name: "character class", patterns: []string{"build-[0-9].tgz"}, want: "build-"},
+		{name: "escape", patterns: []string{`a\*.csv`}, want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newKeyMatcher("", tt.patterns).listPrefix(); got != tt.want {
+				t.Errorf("listPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchObjects(t *testing.T) {
+	keys := []string{
+		"incoming/a.csv",
+		"incoming/b.txt",
+		"incoming/2024/c.csv",
+		"incoming/2024/d.csv",
+		"incomingx/e.csv",
+	}
+
+	tests := []struct {
+		name       string
+		patterns   []string
+		want       []string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{name: "glob", patterns: []string{"*.csv"}, want: []string{"incoming/a.csv"}, wantPrefix: "incoming/"},
+		{name: "nested glob", patterns: []string{"2024/*.csv"}, want: []string{"incoming/2024/c.csv", "incoming/2024/d.csv"}, wantPrefix: "incoming/2024/"},
+		{name: "exact keys", patterns: []string{"a.csv", "b.txt"}, want: []string{"incoming/a.csv", "incoming/b.txt"}, wantPrefix: "incoming/"},
+		{name: "pattern matching nothing", patterns: []string{"a.csv", "missing.csv"}, wantPrefix: "incoming/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, listed := fakeBucket(t, "incoming", keys...)
+			objects, err := matchObjects(context.Background(), client, "incoming", tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchObjects() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, object := range objects {
+				got = append(got, object.Path)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matchObjects() = %v, want %v", got, tt.want)
+			}
+			if len(*listed) != 1 || (*listed)[0] != tt.wantPrefix {
+				t.Errorf("listed prefixes %q, want [%q]", *listed, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+// fakeBucket serves a ListObjectsV2 listing of keys and returns a client
+// for it with the given path prefix, along with the prefixes it was asked
+// to list
+func fakeBucket(t *testing.T, pathPrefix string, keys ...string) (*minioClient.Client, *[]string) {
+	t.Helper()
+
+	type contents struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int64
+	}
+	type listing struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []contents
+	}
+
+	var listed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("list-type") != "2" {
+			http.Error(w, "unexpected request", http.StatusNotImplemented)
+			return
+		}
+		prefix := query.Get("prefix")
+		listed = append(listed, prefix)
+
+		result := listing{Name: "releases", Prefix: prefix, MaxKeys: 1000}
+		for _, key := range keys {
+			if strings.HasPrefix(key, prefix) {
+				result.Contents = append(result.Contents, contents{
+					Key:          key,
+					LastModified: "2024-05-01T12:00:00.000Z",
+					ETag:         `"etag"`,
+					Size:         1,
+				})
+			}
+		}
+		result.KeyCount = len(result.Contents)
+		w.Header().Set("Content-Type", "application/xml")
+		if err := xml.NewEncoder(w).Encode(result); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	useSSL := false
+	client, err := minioClient.NewClient(models.Source{
+		Endpoint:   strings.TrimPrefix(server.URL, "http://"),
+		AccessKey:  "access",
+		SecretKey:  "secret",
+		Bucket:     "releases",
+		Region:     "us-east-1",
+		UseSSL:     &useSSL,
+		PathPrefix: pathPrefix,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &listed
+}
@@ -0,0 +1,359 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+// Out uploads the files in sourceDir matching the file param, if uploads
+// are enabled, or deletes, moves or copies objects already in the bucket
+func Out(ctx context.Context, request models.OutRequest, sourceDir string) (models.OutResponse, error) {
+	logger := newLogger(request.Source, request.Params.LogLevel)
+
+	// Validate source configuration and params
+	if err := validateSource(&request.Source); err != nil {
+		return models.OutResponse{}, err
+	}
+	if err := request.Params.Validate(); err != nil {
+		return models.OutResponse{}, fmt.Errorf("invalid params:\n%w", err)
+	}
+
+	// Promotion, delete, move and copy work on objects already in a bucket
+	if request.Params.PromoteFrom.IsSet() {
+		return promote(ctx, request, sourceDir, logger)
+	}
+	if request.Params.ActionValue() != models.ActionUpload {
+		return manageObjects(ctx, request, logger)
+	}
+
+	// Check if upload is disabled (default behavior for download-only resource)
+	if !request.Params.UploadEnabled {
+		// Return a minimal response for no-op out
+		logger.Info("Upload is disabled. This resource is configured for download-only operation.")
+		logger.Info("To enable uploads, set params.upload_enabled to true in your pipeline.")
+
+		// Return empty version with current timestamp
+		return models.OutResponse{
+			Version: models.Version{
+				Path:         "no-upload",
+				ETag:         "disabled",
+				LastModified: time.Now(),
+			},
+			Metadata: []models.Metadata{
+				{
+					Name:  "upload_status",
+					Value: "disabled",
+				},
+			},
+		}, nil
+	}
+
+	// If upload is enabled, proceed with upload logic
+	// Get the file pattern to upload
+	filePattern := request.Params.File
+	if filePattern == "" {
+		filePattern = "*"
+	}
+
+	// Create Minio client
+	client, err := connect(ctx, &request.Source, request.Source.LimitParams.Merge(request.Params.LimitParams), logger)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	// Find files to upload
+	pattern := filepath.Join(sourceDir, filePattern)
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return models.OutResponse{}, fmt.Errorf("failed to find files with pattern %s: %w", pattern, err)
+	}
+
+	if len(files) == 0 {
+		return models.OutResponse{}, fmt.Errorf("no files found matching pattern: %s", filePattern)
+	}
+
+	// Work out what to upload
+	plan := planUploads(files, sourceDir, request.Source.PathPrefix, logger)
+	if len(plan) == 0 {
+		return models.OutResponse{}, fmt.Errorf("no files found matching pattern: %s", filePattern)
+	}
+	upload := request.Source.UploadParams.Merge(request.Params.UploadParams)
+	if err := checkUploads(plan, upload); err != nil {
+		return models.OutResponse{}, err
+	}
+
+	if request.Params.DryRun {
+		return dryRunOut(logger, plan, models.Metadata{Name: "upload_pattern", Value: filePattern}), nil
+	}
+
+	var uploadedFiles []string
+	var lastVersion models.Version
+
+	// Upload each file
+	var totalBytes int64
+	for _, action := range plan {
+		totalBytes += max(action.Size, 0)
+	}
+	tracker := progress.Start(logger, "Uploading", len(plan), totalBytes,
+		progressOptions(request.Source, request.Params.ProgressInterval, logger))
+
+	var stats models.TransferStats
+	start := time.Now()
+	for _, action := range plan {
+		// Upload file, reopening it for every attempt. Streams cannot be
+		// read again, so they are not retried.
+		logger.Debug("Uploading", "file", action.File, "key", action.Key, "size", action.Size)
+		retries := request.Params.RetriesValue()
+		if action.Size < 0 {
+			retries = 0
+		}
+		size := action.Size
+		retried, err := client.Retry(ctx, retries, action.File, func() error {
+			var err error
+			size, err = uploadFile(ctx, client, action, upload, request.Params.PreserveAttributes, tracker)
+			return err
+		})
+		tracker.FileDone()
+		if ctx.Err() != nil {
+			tracker.Stop()
+			return models.OutResponse{}, fmt.Errorf("upload aborted after %d of %d files: %w", len(uploadedFiles), len(plan), context.Cause(ctx))
+		}
+		addTransfer(&stats, action.Key, max(size, 0), retried, err)
+		if err != nil {
+			logger.Warn("Upload failed", "file", action.File, "error", err)
+			continue
+		}
+
+		uploadedFiles = append(uploadedFiles, action.Key)
+
+		// Keep track of last uploaded file for version
+		lastVersion = models.Version{
+			Path:         action.Key,
+			ETag:         fmt.Sprintf("upload-%d", time.Now().Unix()),
+			LastModified: time.Now(),
+		}
+	}
+
+	tracker.Stop()
+	stats.Finish(time.Since(start))
+
+	if len(uploadedFiles) == 0 {
+		return models.OutResponse{}, fmt.Errorf("no files were uploaded successfully")
+	}
+
+	// Prepare metadata
+	metadata := []models.Metadata{
+		{
+			Name:  "files_uploaded",
+			Value: fmt.Sprintf("%d", len(uploadedFiles)),
+		},
+		{
+			Name:  "upload_pattern",
+			Value: filePattern,
+		},
+	}
+	metadata = append(metadata, stats.Metadata()...)
+
+	// Add presigned download URLs for the uploaded files
+	if request.Params.Presign.Enabled {
+		presignExpiry := request.Params.Presign.ExpiresValue()
+		expiresAt := time.Now().Add(presignExpiry).UTC().Truncate(time.Second)
+		urls := make([]models.PresignedURL, 0, len(uploadedFiles))
+		for _, objectPath := range uploadedFiles {
+			u, err := client.PresignGetObject(ctx, objectPath, presignExpiry)
+			if err != nil {
+				return models.OutResponse{}, fmt.Errorf("failed to presign url: %w", err)
+			}
+			urls = append(urls, models.PresignedURL{Key: objectPath, URL: u, ExpiresAt: expiresAt})
+		}
+		metadata = append(metadata, presignMetadata(urls)...)
+	}
+
+	logger.Info("Upload complete", "uploaded", len(uploadedFiles), "failed", len(plan)-len(uploadedFiles),
+		"size", humanize.IBytes(uint64(stats.Bytes)), "duration", time.Since(start), "retries", stats.Retries)
+
+	return models.OutResponse{
+		Version:  lastVersion,
+		Metadata: metadata,
+	}, nil
+}
+
+// presignMetadata returns one metadata entry per presigned URL, named after
+// the object key so each link can be told apart, followed by their expiry.
+func presignMetadata(urls []models.PresignedURL) []models.Metadata {
+	if len(urls) == 0 {
+		return nil
+	}
+	metadata := make([]models.Metadata, 0, len(urls)+1)
+	for _, u := range urls {
+		metadata = append(metadata, models.Metadata{
+			Name:  u.Key,
+			Value: u.URL,
+		})
+	}
+	return append(metadata, models.Metadata{
+		Name:  "url_expires_at",
+		Value: urls[0].ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// uploadFile uploads a planned file and returns its size, taking back its
+// progress on failure. With preserve, the file's mode and modification time
+// are stored as object metadata.
+func uploadFile(ctx context.Context, client *minioClient.Client, action plannedAction, upload models.UploadParams, preserve bool, tracker *progress.Tracker) (int64, error) {
+	reader, err := os.Open(action.File)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file %s: %w", action.File, err)
+	}
+	defer reader.Close()
+
+	opts := minioClient.PutOptions{
+		ContentType:      action.ContentType,
+		PartSize:         int64(upload.PartSize),
+		Threads:          upload.UploadThreads,
+		DisableMultipart: upload.DisableMultipart,
+	}
+	if preserve {
+		info, err := reader.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat file %s: %w", action.File, err)
+		}
+		opts.UserMetadata = minioClient.FileAttributes(info)
+	}
+
+	counter := tracker.Counter()
+	opts.Progress = counter
+	size, err := client.PutObject(ctx, action.Key, reader, action.Size, opts)
+	if err != nil {
+		counter.Undo()
+	}
+	return size, err
+}
+
+// checkUploads rejects planned uploads that the upload params cannot
+// handle, before anything is uploaded
+func checkUploads(plan []plannedAction, upload models.UploadParams) error {
+	var errs []error
+	for _, action := range plan {
+		switch {
+		case upload.DisableMultipart && action.Size < 0:
+			errs = append(errs, fmt.Errorf("%s has no known size and cannot be uploaded with disable_multipart", action.File))
+		case upload.DisableMultipart && action.Size > int64(models.MaxPartSize):
+			errs = append(errs, fmt.Errorf("%s is %s, larger than the 5GiB disable_multipart allows", action.File, formatSize(action.Size)))
+		case upload.PartSize != 0 && action.Size > int64(upload.PartSize)*models.MaxPartCount:
+			errs = append(errs, fmt.Errorf("%s is %s, more than %d parts of part_size %s", action.File,
+				formatSize(action.Size), models.MaxPartCount, formatSize(int64(upload.PartSize))))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// plannedAction is a single operation out performs on the bucket. Uploads
+// write File to Key, while copies and moves write Key to Target in
+// TargetBucket.
+type plannedAction struct {
+	Action       string
+	File         string
+	Key          string
+	TargetBucket string
+	Target       string
+	Size         int64
+	ContentType  string
+}
+
+// planUploads maps the matched files to the objects they are uploaded to,
+// skipping directories and files that cannot be read. Named pipes and other
+// files that are not regular are planned with a size of -1 and streamed.
+func planUploads(files []string, sourceDir, pathPrefix string, logger *slog.Logger) []plannedAction {
+	var plan []plannedAction
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Warn("Failed to stat file", "file", file, "error", err)
+			continue
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		// Calculate object path
+		relativePath := strings.TrimPrefix(file, sourceDir)
+		relativePath = strings.TrimPrefix(relativePath, "/")
+
+		objectPath := filepath.Join(pathPrefix, relativePath)
+		objectPath = strings.ReplaceAll(objectPath, "\\", "/") // Ensure forward slashes
+
+		size := info.Size()
+		if !info.Mode().IsRegular() {
+			size = -1
+		}
+
+		plan = append(plan, plannedAction{
+			Action:      models.ActionUpload,
+			File:        file,
+			Key:         objectPath,
+			Size:        size,
+			ContentType: "application/octet-stream",
+		})
+	}
+	return plan
+}
+
+// printPlan logs the actions a dry run would have performed
+func printPlan(logger *slog.Logger, plan []plannedAction) {
+	logger.Info("Dry run, the bucket is not modified", "actions", len(plan))
+	for _, action := range plan {
+		message := "Would " + action.Action
+		size := formatSize(action.Size)
+		switch action.Action {
+		case models.ActionUpload:
+			logger.Info(message, "file", action.File, "key", action.Key, "size", size, "content_type", action.ContentType)
+		case models.ActionDelete:
+			logger.Info(message, "key", action.Key, "size", size)
+		default:
+			logger.Info(message, "key", action.Key, "target", action.TargetBucket+"/"+action.Target, "size", size)
+		}
+	}
+}
+
+// formatSize formats a size in bytes, which is -1 for streams
+func formatSize(size int64) string {
+	if size < 0 {
+		return "unknown"
+	}
+	return humanize.IBytes(uint64(size))
+}
+
+// dryRunOut logs the plan and returns the placeholder version of a dry run
+func dryRunOut(logger *slog.Logger, plan []plannedAction, metadata ...models.Metadata) models.OutResponse {
+	printPlan(logger, plan)
+	return models.OutResponse{
+		Version: models.Version{
+			Path:         "dry-run",
+			ETag:         "dry-run",
+			LastModified: time.Now(),
+		},
+		Metadata: append([]models.Metadata{
+			{
+				Name:  "dry_run",
+				Value: "true",
+			},
+			{
+				Name:  "files_planned",
+				Value: fmt.Sprintf("%d", len(plan)),
+			},
+		}, metadata...),
+	}
+}
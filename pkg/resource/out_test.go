@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+func TestPresignMetadata(t *testing.T) {
+	expires := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		urls []models.PresignedURL
+		want []models.Metadata
+	}{
+		{
+			name: "no urls",
+		},
+		{
+			name: "single key",
+			urls: []models.PresignedURL{
+				{Key: "builds/app.tgz", URL: "https://minio/builds/app.tgz?sig=1", ExpiresAt: expires},
+			},
+			want: []models.Metadata{
+				{Name: "builds/app.tgz", Value: "https://minio/builds/app.tgz?sig=1"},
+				{Name: "url_expires_at", Value: "2024-01-02T03:04:05Z"},
+			},
+		},
+		{
+			name: "every url named after its key",
+			urls: []models.PresignedURL{
+				{Key: "builds/app.tgz", URL: "https://minio/builds/app.tgz?sig=1", ExpiresAt: expires},
+				{Key: "builds/app.sha256", URL: "https://minio/builds/app.sha256?sig=2", ExpiresAt: expires},
+			},
+			want: []models.Metadata{
+				{Name: "builds/app.tgz", Value: "https://minio/builds/app.tgz?sig=1"},
+				{Name: "builds/app.sha256", Value: "https://minio/builds/app.sha256?sig=2"},
+				{Name: "url_expires_at", Value: "2024-01-02T03:04:05Z"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := presignMetadata(tt.urls); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("presignMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+)
+
+// promoteTarget returns the key that key is promoted to below pathPrefix,
+// keeping the part of it below prefix, by default just the file name. The
+// prefix must name a directory key lies in, so "build" does not match
+// "builds/app.tgz".
+func promoteTarget(pathPrefix, prefix, key string) (string, error) {
+	if prefix == "" {
+		prefix = strings.TrimSuffix(key, path.Base(key))
+	}
+	if dir := strings.TrimSuffix(prefix, "/"); dir != "" && !strings.HasPrefix(key, dir+"/") {
+		return "", fmt.Errorf("promote_from.prefix %q is not a directory of %q", prefix, key)
+	}
+	if strings.TrimSuffix(relativeKey(key, prefix), "/") == "" {
+		return "", fmt.Errorf("promote_from.prefix %q leaves no key of %q to promote", prefix, key)
+	}
+	return targetKey(pathPrefix, prefix, key), nil
+}
+
+// promote copies the object named by promote_from server-side into this
+// resource's bucket and prefix, so that promoting a build never downloads it
+func promote(ctx context.Context, request models.OutRequest, sourceDir string, logger *slog.Logger) (models.OutResponse, error) {
+	from := request.Params.PromoteFrom
+
+	// Read the version fetched by the get step
+	if from.Dir != "" {
+		versionFile := filepath.Join(sourceDir, from.Dir, ".resource_version.json")
+		data, err := os.ReadFile(versionFile)
+		if err != nil {
+			return models.OutResponse{}, fmt.Errorf("failed to read version of promote_from: %w", err)
+		}
+
+		var version models.Version
+		if err := json.Unmarshal(data, &version); err != nil {
+			return models.OutResponse{}, fmt.Errorf("failed to parse %s: %w", versionFile, err)
+		}
+		if version.Path == "" {
+			return models.OutResponse{}, fmt.Errorf("%s does not name an object to promote", versionFile)
+		}
+		from.Path = version.Path
+		from.ETag = version.ETag
+	}
+	if from.Bucket == "" {
+		from.Bucket = request.Source.Bucket
+	}
+
+	target, err := promoteTarget(request.Source.PathPrefix, from.Prefix, from.Path)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	if from.Bucket == request.Source.Bucket && from.Path == target {
+		return models.OutResponse{}, fmt.Errorf("%s/%s is already in place", from.Bucket, from.Path)
+	}
+
+	// Create Minio client
+	client, err := connect(ctx, &request.Source, request.Source.LimitParams.Merge(request.Params.LimitParams), logger)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	if request.Params.DryRun {
+		return dryRunOut(logger, []plannedAction{{
+			Action:       "promote",
+			Key:          from.Bucket + "/" + from.Path,
+			TargetBucket: request.Source.Bucket,
+			Target:       target,
+		}}), nil
+	}
+
+	// Copy the exact version that was fetched
+	logger.Info("Promoting", "key", from.Bucket+"/"+from.Path, "target", request.Source.Bucket+"/"+target)
+	info, err := client.CopyObject(ctx, minioClient.CopySource{
+		Bucket: from.Bucket,
+		Key:    from.Path,
+		ETag:   from.ETag,
+	}, request.Source.Bucket, target)
+	if err != nil {
+		return models.OutResponse{}, err
+	}
+
+	lastModified := info.LastModified
+	if lastModified.IsZero() {
+		lastModified = time.Now()
+	}
+
+	metadata := []models.Metadata{
+		{
+			Name:  "promoted_from",
+			Value: from.Bucket + "/" + from.Path,
+		},
+		{
+			Name:  "promoted_etag",
+			Value: from.ETag,
+		},
+		{
+			Name:  "size",
+			Value: fmt.Sprintf("%d", info.Size),
+		},
+	}
+
+	// Add a presigned download URL for the promoted object
+	if request.Params.Presign.Enabled {
+		presignExpiry := request.Params.Presign.ExpiresValue()
+		u, err := client.PresignGetObject(ctx, target, presignExpiry)
+		if err != nil {
+			return models.OutResponse{}, fmt.Errorf("failed to presign url: %w", err)
+		}
+		metadata = append(metadata,
+			models.Metadata{
+				Name:  "url",
+				Value: u,
+			},
+			models.Metadata{
+				Name:  "url_expires_at",
+				Value: time.Now().Add(presignExpiry).UTC().Format(time.RFC3339),
+			},
+		)
+	}
+
+	logger.Info("Promotion complete", "key", target, "size", humanize.IBytes(uint64(info.Size)))
+
+	return models.OutResponse{
+		Version: models.Version{
+			Path:         target,
+			ETag:         info.ETag,
+			LastModified: lastModified,
+		},
+		Metadata: metadata,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package resource
+
+import "testing"
+
+func TestPromoteTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		pathPrefix string
+		prefix     string
+		key        string
+		want       string
+		wantErr    bool
+	}{
+		{name: "file name by default", pathPrefix: "releases", key: "builds/42/app.tgz", want: "releases/app.tgz"},
+		{name: "key at the bucket root", pathPrefix: "releases", key: "app.tgz", want: "releases/app.tgz"},
+		{name: "no path prefix", key: "builds/42/app.tgz", want: "app.tgz"},
+		{name: "prefix keeps subdirectories", pathPrefix: "releases", prefix: "builds", key: "builds/42/app.tgz", want: "releases/42/app.tgz"},
+		{name: "prefix with slash", pathPrefix: "releases/", prefix: "builds/", key: "builds/42/app.tgz", want: "releases/42/app.tgz"},
+		{name: "no path prefix with prefix", prefix: "incoming", key: "incoming/a/b.txt", want: "a/b.txt"},
+		{name: "prefix sharing a start", pathPrefix: "releases", prefix: "build", key: "builds/42/app.tgz", wantErr: true},
+		{name: "prefix outside key", pathPrefix: "releases", prefix: "other", key: "builds/42/app.tgz", wantErr: true},
+		{name: "prefix is the key", pathPrefix: "releases", prefix: "builds/42/app.tgz", key: "builds/42/app.tgz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := promoteTarget(tt.pathPrefix, tt.prefix, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("promoteTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("promoteTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
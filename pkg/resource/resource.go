@@ -0,0 +1,104 @@
+// Package resource implements the check, in and out steps of the Concourse
+// resource. The scripts in cmd/ and the developer CLI read requests and write
+// responses around these functions.
+package resource
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/zinc-sig/minio-resource/pkg/logging"
+	minioClient "github.com/zinc-sig/minio-resource/pkg/minio"
+	"github.com/zinc-sig/minio-resource/pkg/models"
+	"github.com/zinc-sig/minio-resource/pkg/progress"
+)
+
+// newLogger returns the logger of a request, writing to stderr at the params
+// log level if set, otherwise at the source's
+func newLogger(source models.Source, level models.LogLevel) *slog.Logger {
+	if level == "" {
+		level = source.LogLevel
+	}
+	return logging.New(os.Stderr, logging.Options{
+		Level:   string(level),
+		Format:  string(source.LogFormat),
+		Secrets: []string{source.AccessKey, source.SecretKey},
+	})
+}
+
+// progressOptions returns how transfers report progress. On a terminal, as
+// when running the CLI, a status line is redrawn every second; otherwise a
+// line is logged every progress_interval. Progress is reported at info
+// level, so a higher log level turns it off.
+func progressOptions(source models.Source, interval time.Duration, logger *slog.Logger) progress.Options {
+	if interval == 0 {
+		interval = source.ProgressInterval
+	}
+	if !logger.Enabled(context.Background(), slog.LevelInfo) {
+		return progress.Options{}
+	}
+
+	if source.LogFormat != logging.FormatJSON && progress.IsTerminal(os.Stderr) {
+		if interval == 0 {
+			interval = time.Second
+		}
+		return progress.Options{Interval: interval, Terminal: os.Stderr}
+	}
+
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	return progress.Options{Interval: interval}
+}
+
+// addTransfer records a file in stats, classifying the error of every
+// failed attempt
+func addTransfer(stats *models.TransferStats, path string, size int64, retried []error, err error) {
+	classes := make([]string, 0, len(retried)+1)
+	for _, attemptErr := range retried {
+		classes = append(classes, minioClient.ErrorClass(attemptErr))
+	}
+	if err != nil {
+		classes = append(classes, minioClient.ErrorClass(err))
+	}
+	stats.Add(path, size, err != nil, len(retried), classes)
+}
+
+// validateSource normalises and validates the source configuration
+func validateSource(source *models.Source) error {
+	if err := source.Validate(); err != nil {
+		return fmt.Errorf("invalid source configuration:\n%w", err)
+	}
+	return nil
+}
+
+// connect validates the source configuration, creates a Minio client held
+// to limits and checks that the bucket is accessible
+func connect(ctx context.Context, source *models.Source, limits models.LimitParams, logger *slog.Logger) (*minioClient.Client, error) {
+	// Validate source configuration
+	if err := validateSource(source); err != nil {
+		return nil, err
+	}
+
+	// Create Minio client
+	client, err := minioClient.NewClient(*source, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create minio client: %w", err)
+	}
+	client.SetLimits(int64(limits.MaxBandwidth), limits.MaxRequestsPerSecond)
+
+	// Check bucket exists
+	exists, err := client.BucketExists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("bucket %s does not exist or is not accessible", source.Bucket)
+	}
+	logger.Debug("Connected", "endpoint", source.Endpoint, "bucket", source.Bucket, "path_prefix", source.PathPrefix)
+
+	return client, nil
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Interrupted is the cause of a context canceled by WithInterrupt
+type Interrupted struct {
+	Signal os.Signal
+}
+
+func (e *Interrupted) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+// WithInterrupt returns a context that is canceled when the process
+// receives SIGINT or SIGTERM, which Concourse sends when a build is
+// aborted, so that transfers stop and clean up after themselves. A second
+// signal terminates the process immediately. Stop releases the signal
+// handler and returns the signal received, if any.
+func WithInterrupt(parent context.Context) (ctx context.Context, stop func() os.Signal) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case sig := <-signals:
+			// Restore the default handling for a second signal
+			signal.Stop(signals)
+			fmt.Fprintf(os.Stderr, "Received %s, aborting\n", sig)
+			received <- sig
+			cancel(&Interrupted{Signal: sig})
+		case <-done:
+		}
+	}()
+
+	return ctx, func() os.Signal {
+		signal.Stop(signals)
+		close(done)
+		<-finished
+		cancel(nil)
+		select {
+		case sig := <-received:
+			return sig
+		default:
+			return nil
+		}
+	}
+}
+
+// ExitCode returns the exit code of a command interrupted by sig, which
+// is 128 plus the signal number as for a process killed by it
+func ExitCode(sig os.Signal) int {
+	if number, ok := sig.(syscall.Signal); ok {
+		return 128 + int(number)
+	}
+	return 1
+}